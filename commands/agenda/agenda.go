@@ -0,0 +1,203 @@
+package agenda
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jomei/notionapi"
+
+	"github.com/crockeo/notion-cli/commands"
+	"github.com/crockeo/notion-cli/config"
+	"github.com/crockeo/notion-cli/database"
+	"github.com/crockeo/notion-cli/errors"
+	"github.com/crockeo/notion-cli/parse"
+)
+
+var (
+	flags = flag.NewFlagSet("agenda", flag.ContinueOnError)
+	days  = flags.Int("days", 7, "Number of days to show, starting with today.")
+)
+
+var Command = &commands.Command{
+	Name:     "agenda",
+	Summary:  "Show open items grouped by their due date.",
+	Usage:    "[flags]",
+	Examples: []string{"agenda", "agenda -days 1"},
+	Flags:    flags,
+	Run:      Agenda,
+}
+
+// item is a page with the properties the agenda cares about pulled out.
+type item struct {
+	Title     string
+	Date      time.Time
+	Timeless  bool
+	Priority  string
+	Rank      int
+	Tags      []string
+	Completed bool
+}
+
+func Agenda(config *config.Config, client *notionapi.Client, args []string) error {
+	dateProperty := config.Properties.Date
+	if dateProperty == "" {
+		return errors.New(errors.KindConfig, "config.Properties.Date must be set to use agenda")
+	}
+
+	databaseChan, errChan := database.Get(config, client)
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	end := notionapi.Date(today.AddDate(0, 0, *days))
+
+	// everything open which is due before the end of the window,
+	// which includes everything that's overdue
+	filters := []notionapi.PropertyFilter{
+		{
+			Property: dateProperty,
+			Date:     &notionapi.DateFilterCondition{Before: &end},
+		},
+	}
+	if openFilter := database.OpenFilter(config); openFilter != nil {
+		filters = append(filters, *openFilter)
+	}
+	pages, err := database.QueryAll(config, client, &notionapi.DatabaseQueryRequest{
+		CompoundFilter: &notionapi.CompoundFilter{notionapi.FilterOperatorAND: filters},
+	})
+	if err != nil {
+		return err
+	}
+
+	// and then the things we've finished today,
+	// so that we can see what we got done
+	completedPages := []notionapi.Page{}
+	if config.Complete.CompletedProperty != "" {
+		completedPages, err = database.QueryDay(config, client, config.Complete.CompletedProperty, today)
+		if err != nil {
+			return err
+		}
+	}
+
+	db, err := database.Join(databaseChan, errChan)
+	if err != nil {
+		return err
+	}
+
+	items := []item{}
+	for i := range pages {
+		item := newItem(config, db, &pages[i])
+		if !item.Date.IsZero() {
+			items = append(items, item)
+		}
+	}
+	for i := range completedPages {
+		// things we finished today show up under today,
+		// no matter when they were due
+		item := newItem(config, db, &completedPages[i])
+		item.Completed = true
+		item.Date = today
+		item.Timeless = true
+		items = append(items, item)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if !items[i].Date.Equal(items[j].Date) {
+			return items[i].Date.Before(items[j].Date)
+		}
+		if items[i].Completed != items[j].Completed {
+			return !items[i].Completed
+		}
+		return items[i].Rank < items[j].Rank
+	})
+
+	printAgenda(items, today)
+	return nil
+}
+
+func newItem(config *config.Config, db *notionapi.Database, page *notionapi.Page) item {
+	// items without a priority sort after those with one
+	item := item{
+		Title: database.PageTitle(page),
+		Rank:  math.MaxInt32,
+	}
+
+	if date, ok := page.Properties[config.Properties.Date].(*notionapi.DateProperty); ok && date.Date.Start != nil {
+		item.Date, item.Timeless = parse.FromNotionDate(date.Date.Start)
+	}
+
+	if priority, ok := page.Properties[config.Properties.Priority].(*notionapi.SelectProperty); ok {
+		item.Priority = priority.Select.Name
+		// priorities are ranked by the order of the select's options
+		if propConfig, ok := db.Properties[config.Properties.Priority].(*notionapi.SelectPropertyConfig); ok {
+			for i, option := range propConfig.Select.Options {
+				if option.Name == item.Priority {
+					item.Rank = i
+				}
+			}
+		}
+	}
+	if tags, ok := page.Properties[config.Properties.Tags].(*notionapi.MultiSelectProperty); ok {
+		for _, option := range tags.MultiSelect {
+			item.Tags = append(item.Tags, option.Name)
+		}
+	}
+
+	return item
+}
+
+func printAgenda(items []item, today time.Time) {
+	header := ""
+	for _, item := range items {
+		itemHeader := dayHeader(item.Date, today)
+		if itemHeader != header {
+			if header != "" {
+				fmt.Println("")
+			}
+			fmt.Println(itemHeader)
+			header = itemHeader
+		}
+		fmt.Println("  " + formatItem(item, today))
+	}
+}
+
+func dayHeader(date time.Time, today time.Time) string {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, today.Location())
+	switch {
+	case day.Before(today):
+		return "Overdue"
+	case day.Equal(today):
+		return "Today"
+	case day.Equal(today.AddDate(0, 0, 1)):
+		return "Tomorrow"
+	case day.Before(today.AddDate(0, 0, 7)):
+		return day.Format("Monday")
+	}
+	return day.Format("Monday, Jan 2")
+}
+
+func formatItem(item item, today time.Time) string {
+	parts := []string{}
+	if item.Completed {
+		parts = append(parts, "[x]")
+	} else {
+		parts = append(parts, "[ ]")
+	}
+	if !item.Timeless {
+		parts = append(parts, item.Date.Format("15:04"))
+	}
+	parts = append(parts, item.Title)
+	if item.Priority != "" {
+		parts = append(parts, "!"+item.Priority)
+	}
+	for _, tag := range item.Tags {
+		parts = append(parts, "#"+tag)
+	}
+	if !item.Completed && item.Date.Before(today) {
+		parts = append(parts, "(due "+item.Date.Format("Mon Jan 2")+")")
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,86 @@
+package appendbody
+
+import (
+	"flag"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jomei/notionapi"
+
+	"github.com/crockeo/notion-cli/commands"
+	"github.com/crockeo/notion-cli/config"
+	"github.com/crockeo/notion-cli/errors"
+	"github.com/crockeo/notion-cli/markdown"
+)
+
+var (
+	flags         = flag.NewFlagSet("append", flag.ContinueOnError)
+	heading       = flags.Bool("heading", false, "Put what's appended under a heading with today's date.")
+	headingFormat = flags.String("heading-format", "Monday, January 2, 2006", "Go time format of the -heading.")
+	todo          = flags.Bool("todo", false, "Append paragraphs and list items as to-dos.")
+)
+
+var Command = &commands.Command{
+	Name:    "append",
+	Summary: "Append Markdown to the body of an existing page.",
+	Usage:   "[flags] <page> [file|-]",
+	Examples: []string{
+		"append 'Reading log' notes.md",
+		"append -heading https://www.notion.so/Standup-0123456789abcdef0123456789abcdef",
+		"echo 'Call the landlord' | append -todo 0123456789abcdef0123456789abcdef -",
+	},
+	Flags: flags,
+	Run:   Append,
+}
+
+func Append(config *config.Config, client *notionapi.Client, args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return errors.New(errors.KindUsage, "expected a page, and optionally a file or - for stdin")
+	}
+
+	page, err := commands.FindPage(config, client, args[0])
+	if err != nil {
+		return err
+	}
+
+	var contents []byte
+	switch {
+	case len(args) == 1:
+		contents, err = commands.EditBody(nil)
+	case args[1] == "-":
+		contents, err = io.ReadAll(os.Stdin)
+	default:
+		contents, err = os.ReadFile(args[1])
+	}
+	if err != nil {
+		return err
+	}
+
+	var title string
+	if *heading {
+		title = time.Now().Format(*headingFormat)
+	}
+	return AppendMarkdown(client, page, contents, title, *todo)
+}
+
+// AppendMarkdown appends contents to the end of page,
+// under a heading when title isn't empty.
+func AppendMarkdown(client *notionapi.Client, page *notionapi.Page, contents []byte, title string, todo bool) error {
+	if strings.TrimSpace(string(contents)) == "" {
+		return errors.New(errors.KindUsage, "nothing to append")
+	}
+
+	blocks, err := markdown.ToBlocks(contents)
+	if err != nil {
+		return errors.Wrap(errors.KindValidation, err, "could not convert the Markdown to blocks")
+	}
+	if todo {
+		blocks = markdown.ToTodos(blocks)
+	}
+	if title != "" {
+		blocks = append([]notionapi.Block{markdown.Heading(2, title)}, blocks...)
+	}
+	return commands.AppendBlocks(client, notionapi.BlockID(page.ID), blocks)
+}
@@ -0,0 +1,220 @@
+package archive
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jomei/notionapi"
+	"github.com/manifoldco/promptui"
+
+	"github.com/crockeo/notion-cli/commands"
+	"github.com/crockeo/notion-cli/config"
+	"github.com/crockeo/notion-cli/database"
+	"github.com/crockeo/notion-cli/errors"
+	"github.com/crockeo/notion-cli/filter"
+)
+
+var (
+	archiveFlags = flag.NewFlagSet("archive", flag.ContinueOnError)
+	yes          = archiveFlags.Bool("yes", false, "Archive without asking for confirmation.")
+
+	restoreFlags = flag.NewFlagSet("restore", flag.ContinueOnError)
+	last         = restoreFlags.Bool("last", false, "Restore every page from the most recent archive.")
+	list         = restoreFlags.Bool("list", false, "List the pages which have been archived, newest first.")
+)
+
+var Command = &commands.Command{
+	Name:    "archive",
+	Summary: "Archive pages by ID, URL or filter expression.",
+	Usage:   "[flags] <page-id...|filter>",
+	Examples: []string{
+		"archive 0123456789abcdef0123456789abcdef",
+		"archive 'Done=true and edited<30 days ago'",
+		"archive -yes 'Tags~Someday'",
+	},
+	Flags:    archiveFlags,
+	Complete: commands.CompleteProperties,
+	Run:      Archive,
+}
+
+var RestoreCommand = &commands.Command{
+	Name:     "restore",
+	Summary:  "Restore archived pages.",
+	Usage:    "[flags] [page-id...]",
+	Examples: []string{"restore -last", "restore -list", "restore 0123456789abcdef0123456789abcdef"},
+	Flags:    restoreFlags,
+	Run:      Restore,
+}
+
+func Archive(config *config.Config, client *notionapi.Client, args []string) error {
+	if len(args) == 0 {
+		return errors.New(errors.KindUsage, "expected page IDs or a filter expression")
+	}
+
+	pages, err := getPages(config, client, args)
+	if err != nil {
+		return err
+	}
+	if len(pages) == 0 {
+		fmt.Println("nothing to archive")
+		return nil
+	}
+
+	for i := range pages {
+		fmt.Println("  " + database.PageTitle(&pages[i]))
+	}
+	if !*yes {
+		prompt := promptui.Prompt{
+			Label:     fmt.Sprintf("Archive %d pages", len(pages)),
+			IsConfirm: true,
+		}
+		if _, err := prompt.Run(); err != nil {
+			return err
+		}
+	}
+
+	// archived pages are logged as we go,
+	// so that a failure part way through can still be undone
+	batch := time.Now().Format(time.RFC3339Nano)
+	for i := range pages {
+		page := &pages[i]
+		if err := setArchived(client, page, true); err != nil {
+			return err
+		}
+		if commands.DryRun {
+			continue
+		}
+		err := appendLog(entry{
+			PageID:     string(page.ID),
+			Title:      database.PageTitle(page),
+			ArchivedAt: time.Now(),
+			Batch:      batch,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if !commands.DryRun {
+		fmt.Fprintf(os.Stderr, "archived %d pages, run '%s restore -last' to undo\n", len(pages), os.Args[0])
+	}
+	return nil
+}
+
+// getPages finds the pages to archive:
+// either every argument is a page, or together they're a filter.
+func getPages(config *config.Config, client *notionapi.Client, args []string) ([]notionapi.Page, error) {
+	pageIDs := []notionapi.PageID{}
+	for _, arg := range args {
+		pageID, ok := database.ParsePageID(arg)
+		if !ok {
+			pageIDs = nil
+			break
+		}
+		pageIDs = append(pageIDs, pageID)
+	}
+
+	if pageIDs != nil {
+		pages := []notionapi.Page{}
+		for _, pageID := range pageIDs {
+			page, err := client.Page.Get(context.Background(), pageID)
+			if err != nil {
+				return nil, err
+			}
+			pages = append(pages, *page)
+		}
+		return pages, nil
+	}
+
+	db, err := database.GetSync(config, client)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := filter.Parse(strings.Join(args, " "), db)
+	if err != nil {
+		return nil, err
+	}
+	return filter.Query(config, client)
+}
+
+func Restore(config *config.Config, client *notionapi.Client, args []string) error {
+	entries, err := readLog()
+	if err != nil {
+		return err
+	}
+
+	if *list {
+		for i := len(entries) - 1; i >= 0; i-- {
+			fmt.Printf("%s  %s  %s\n", entries[i].ArchivedAt.Local().Format("2006-01-02 15:04"), entries[i].PageID, entries[i].Title)
+		}
+		return nil
+	}
+
+	pageIDs := []string{}
+	switch {
+	case *last && len(args) > 0:
+		return errors.New(errors.KindUsage, "expected either -last or page IDs, not both")
+	case *last:
+		if len(entries) == 0 {
+			return errors.New(errors.KindNotFound, "nothing has been archived")
+		}
+		batch := entries[len(entries)-1].Batch
+		for _, entry := range entries {
+			if entry.Batch == batch {
+				pageIDs = append(pageIDs, entry.PageID)
+			}
+		}
+	case len(args) == 0:
+		return errors.New(errors.KindUsage, "expected -last or page IDs to restore")
+	default:
+		for _, arg := range args {
+			pageID, ok := database.ParsePageID(arg)
+			if !ok {
+				return errors.New(errors.KindUsage, "'%s' is not a page ID or URL", arg)
+			}
+			pageIDs = append(pageIDs, string(pageID))
+		}
+	}
+
+	// whatever we manage to restore comes out of the log,
+	// even when something fails part way through
+	restored := map[string]bool{}
+	var restoreErr error
+	for _, pageID := range pageIDs {
+		page := &notionapi.Page{ID: notionapi.ObjectID(pageID), Archived: true}
+		if restoreErr = setArchived(client, page, false); restoreErr != nil {
+			break
+		}
+		restored[database.NormalizeID(pageID)] = true
+	}
+	if commands.DryRun {
+		return restoreErr
+	}
+
+	remaining := []entry{}
+	for _, entry := range entries {
+		if !restored[database.NormalizeID(entry.PageID)] {
+			remaining = append(remaining, entry)
+		}
+	}
+	if err := writeLog(remaining); err != nil {
+		return err
+	}
+	if restoreErr != nil {
+		return restoreErr
+	}
+	fmt.Fprintf(os.Stderr, "restored %d pages\n", len(pageIDs))
+	return nil
+}
+
+func setArchived(client *notionapi.Client, page *notionapi.Page, archived bool) error {
+	_, err := commands.UpdatePage(client, page, &notionapi.PageUpdateRequest{
+		Properties: notionapi.Properties{},
+		Archived:   archived,
+	})
+	return err
+}
@@ -0,0 +1,85 @@
+package archive
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/crockeo/notion-cli/config"
+)
+
+// entry records a page we archived,
+// so that it can be restored without digging through Notion's trash.
+// pages archived by the same command share a Batch.
+type entry struct {
+	PageID     string    `json:"page_id"`
+	Title      string    `json:"title"`
+	ArchivedAt time.Time `json:"archived_at"`
+	Batch      string    `json:"batch"`
+}
+
+func logPath() (string, error) {
+	return config.StatePath("archived.jsonl")
+}
+
+func readLog() ([]entry, error) {
+	path, err := logPath()
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return []entry{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	entries := []entry{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry entry
+		// a line we can't read shouldn't lose us the rest of the log
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err == nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+func appendLog(entry entry) error {
+	path, err := logPath()
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	bytes, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(append(bytes, '\n'))
+	return err
+}
+
+func writeLog(entries []entry) error {
+	path, err := logPath()
+	if err != nil {
+		return err
+	}
+	contents := []byte{}
+	for _, entry := range entries {
+		bytes, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		contents = append(contents, bytes...)
+		contents = append(contents, '\n')
+	}
+	return os.WriteFile(path, contents, 0600)
+}
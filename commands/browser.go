@@ -0,0 +1,21 @@
+package commands
+
+import (
+	"os/exec"
+	"runtime"
+)
+
+// OpenURL opens url with the platform's default handler,
+// which for Notion URLs is usually the browser or the desktop app.
+func OpenURL(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
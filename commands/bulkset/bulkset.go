@@ -0,0 +1,370 @@
+package bulkset
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jomei/notionapi"
+	"github.com/manifoldco/promptui"
+
+	"github.com/crockeo/notion-cli/commands"
+	"github.com/crockeo/notion-cli/config"
+	"github.com/crockeo/notion-cli/database"
+	"github.com/crockeo/notion-cli/errors"
+	"github.com/crockeo/notion-cli/filter"
+	"github.com/crockeo/notion-cli/parse"
+)
+
+var (
+	flags       = flag.NewFlagSet("bulk-set", flag.ContinueOnError)
+	where       = flags.String("where", "", "Filter expression selecting the pages to update, e.g. 'Tags~Q3 and created<60 days ago'.")
+	concurrency = flags.Int("concurrency", 3, "Number of pages updated at once.")
+	rate        = flags.Float64("rate", 3, "Maximum number of updates sent per second.")
+	yes         = flags.Bool("yes", false, "Update without asking for confirmation.")
+	restart     = flags.Bool("restart", false, "Ignore the progress of an earlier run which was interrupted.")
+)
+
+var Command = &commands.Command{
+	Name:    "bulk-set",
+	Summary: "Set properties on every page matching a filter.",
+	Usage:   "-where <filter> [flags] Prop=value|Prop+=value|Prop-=value...",
+	Examples: []string{
+		"bulk-set -where 'Tags~Q3' Tags-=Q3 Tags+=Q4",
+		"bulk-set -where 'Priority!=Low and created<60 days ago' Priority=Low",
+		"--dry-run bulk-set -where 'Due<today and Done=false' Due=tomorrow",
+	},
+	Flags:    flags,
+	Complete: commands.CompleteProperties,
+	Run:      BulkSet,
+}
+
+// sampleSize is how many titles we show before asking to go ahead.
+const sampleSize = 5
+
+func BulkSet(config *config.Config, client *notionapi.Client, args []string) error {
+	if *where == "" {
+		return errors.New(errors.KindUsage, "-where is required, so that we never update the whole database by accident")
+	}
+	if len(args) == 0 {
+		return errors.New(errors.KindUsage, "expected at least one Prop=value to set")
+	}
+	if *rate <= 0 {
+		return errors.New(errors.KindUsage, "-rate must be positive")
+	}
+
+	db, err := database.GetSync(config, client)
+	if err != nil {
+		return err
+	}
+
+	properties, edits, err := getProperties(db, args)
+	if err != nil {
+		return err
+	}
+
+	filter, err := filter.Parse(*where, db)
+	if err != nil {
+		return err
+	}
+
+	pages, err := filter.Query(config, client)
+	if err != nil {
+		return err
+	}
+
+	progress, err := openProgress(args)
+	if err != nil {
+		return err
+	}
+	if *restart {
+		if err := progress.remove(); err != nil {
+			return err
+		}
+		progress.done = map[string]bool{}
+	}
+	pending := []notionapi.Page{}
+	for _, page := range pages {
+		if !progress.done[string(page.ID)] {
+			pending = append(pending, page)
+		}
+	}
+	if skipped := len(pages) - len(pending); skipped > 0 {
+		fmt.Printf("resuming, %d pages were already updated\n", skipped)
+	}
+	if len(pending) == 0 {
+		fmt.Println("nothing to update")
+		return progress.remove()
+	}
+
+	preview(pending)
+	if !*yes && !commands.DryRun {
+		prompt := promptui.Prompt{
+			Label:     fmt.Sprintf("Update %d pages", len(pending)),
+			IsConfirm: true,
+		}
+		if _, err := prompt.Run(); err != nil {
+			return err
+		}
+	}
+
+	errs := update(client, pending, properties, edits, progress)
+	if commands.DryRun {
+		fmt.Fprintf(os.Stderr, "would update %d pages\n", len(pending))
+		return nil
+	}
+
+	failed := 0
+	var firstErr error
+	for _, err := range errs {
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			failed++
+		}
+	}
+	fmt.Printf("updated %d of %d pages\n", len(pending)-failed, len(pending))
+	if firstErr != nil {
+		return errors.Wrap(errors.KindOf(firstErr), firstErr, "failed to update %d pages, run the same command again to retry them", failed)
+	}
+	return progress.remove()
+}
+
+// edit adds options to, or removes them from, a multi_select or relation,
+// which we can only do by merging with each page's current value.
+type edit struct {
+	propName string
+	remove   bool
+	property notionapi.Property
+}
+
+// getProperties parses each Prop=value the same way capture does,
+// and each Prop+=value and Prop-=value into an edit.
+func getProperties(db *notionapi.Database, args []string) (notionapi.Properties, []edit, error) {
+	properties := notionapi.Properties{}
+	edits := []edit{}
+	for _, arg := range args {
+		i := strings.Index(arg, "=")
+		if i < 0 {
+			return nil, nil, errors.New(errors.KindUsage, "expected Prop=value, got '%s'", arg)
+		}
+		propName, propValue := arg[:i], arg[i+1:]
+
+		operator := "="
+		if _, ok := db.Properties[propName]; !ok && (strings.HasSuffix(propName, "+") || strings.HasSuffix(propName, "-")) {
+			operator = propName[len(propName)-1:] + "="
+			propName = propName[:len(propName)-1]
+		}
+
+		propConfig, ok := db.Properties[propName]
+		if !ok {
+			return nil, nil, errors.New(errors.KindValidation, "property '%s' does not exist in the database", propName)
+		}
+		mergeable := false
+		switch propConfig.(type) {
+		case *notionapi.MultiSelectPropertyConfig, *notionapi.RelationPropertyConfig:
+			mergeable = true
+		}
+		if operator == "=" && mergeable {
+			return nil, nil, errors.New(errors.KindUsage, "'%s' would replace every page's %s, use %s+=%s or %s-=%s instead", arg, propConfig.GetType(), propName, propValue, propName, propValue)
+		}
+		if operator != "=" && !mergeable {
+			return nil, nil, errors.New(errors.KindUsage, "'%s' is a %s, but %s only works on multi_select and relation properties", propName, propConfig.GetType(), operator)
+		}
+
+		property, err := parse.Property(propName, propConfig, propValue)
+		if err != nil {
+			return nil, nil, errors.Wrap(errors.KindValidation, err, "invalid value for '%s'", propName)
+		}
+		if property == nil || reflect.ValueOf(property).Kind() == reflect.Ptr && reflect.ValueOf(property).IsNil() {
+			return nil, nil, errors.New(errors.KindValidation, "bulk-set can't clear '%s', it needs a value", propName)
+		}
+		if operator == "=" {
+			properties[propName] = property
+		} else {
+			edits = append(edits, edit{propName: propName, remove: operator == "-=", property: property})
+		}
+	}
+	return properties, edits, nil
+}
+
+// merge applies the edits to the page's current values,
+// on top of the properties which are set outright.
+func merge(page *notionapi.Page, properties notionapi.Properties, edits []edit) notionapi.Properties {
+	merged := notionapi.Properties{}
+	for propName, property := range properties {
+		merged[propName] = property
+	}
+	for _, edit := range edits {
+		current, ok := merged[edit.propName]
+		if !ok {
+			current = page.Properties[edit.propName]
+		}
+
+		switch property := edit.property.(type) {
+		case *notionapi.MultiSelectProperty:
+			options := []notionapi.Option{}
+			if current, ok := current.(*notionapi.MultiSelectProperty); ok {
+				options = current.MultiSelect
+			}
+			kept := []notionapi.Option{}
+			for _, option := range options {
+				if !hasOption(property.MultiSelect, option.Name) {
+					kept = append(kept, option)
+				}
+			}
+			if !edit.remove {
+				kept = append(kept, property.MultiSelect...)
+			}
+			merged[edit.propName] = &notionapi.MultiSelectProperty{MultiSelect: kept}
+		case *notionapi.RelationProperty:
+			relations := []notionapi.Relation{}
+			if current, ok := current.(*notionapi.RelationProperty); ok {
+				relations = current.Relation
+			}
+			kept := []notionapi.Relation{}
+			for _, relation := range relations {
+				if !hasRelation(property.Relation, relation.ID) {
+					kept = append(kept, notionapi.Relation{ID: relation.ID})
+				}
+			}
+			if !edit.remove {
+				kept = append(kept, property.Relation...)
+			}
+			merged[edit.propName] = &notionapi.RelationProperty{Relation: kept}
+		}
+	}
+	return merged
+}
+
+func hasOption(options []notionapi.Option, name string) bool {
+	for _, option := range options {
+		if option.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasRelation(relations []notionapi.Relation, id notionapi.PageID) bool {
+	for _, relation := range relations {
+		if database.NormalizeID(string(relation.ID)) == database.NormalizeID(string(id)) {
+			return true
+		}
+	}
+	return false
+}
+
+func preview(pages []notionapi.Page) {
+	fmt.Printf("%d pages match, including:\n", len(pages))
+	for i := 0; i < len(pages) && i < sampleSize; i++ {
+		fmt.Println("  " + database.PageTitle(&pages[i]))
+	}
+	if len(pages) > sampleSize {
+		fmt.Printf("  ...and %d more\n", len(pages)-sampleSize)
+	}
+}
+
+// update sends the updates from a pool of workers,
+// no faster than -rate so that we don't trip Notion's rate limit.
+// it returns the error for each page, which is nil for the pages that were updated.
+func update(client *notionapi.Client, pages []notionapi.Page, properties notionapi.Properties, edits []edit, progress *progress) []error {
+	workers := *concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	limiter := time.NewTicker(time.Duration(float64(time.Second) / *rate))
+	defer limiter.Stop()
+
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	errs := make([]error, len(pages))
+	sem := make(chan struct{}, workers)
+	for i := range pages {
+		<-limiter.C
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, page *notionapi.Page) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			_, err := commands.UpdatePage(client, page, &notionapi.PageUpdateRequest{Properties: merge(page, properties, edits)})
+			lock.Lock()
+			defer lock.Unlock()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", database.PageTitle(page), err.Error())
+				errs[i] = err
+				return
+			}
+			if !commands.DryRun {
+				if err := progress.add(string(page.ID)); err != nil {
+					fmt.Fprintf(os.Stderr, "failed to record progress: %s\n", err.Error())
+				}
+			}
+		}(i, &pages[i])
+	}
+	wg.Wait()
+	return errs
+}
+
+// progress remembers which pages a run has updated,
+// so that running the same command after an interruption
+// picks up where it left off.
+type progress struct {
+	path string
+	done map[string]bool
+}
+
+// openProgress finds the progress for the same -where and assignments.
+func openProgress(args []string) (*progress, error) {
+	sorted := append([]string{}, args...)
+	sort.Strings(sorted)
+	hash := sha256.Sum256([]byte(*where + "\x00" + strings.Join(sorted, "\x00")))
+
+	path, err := config.StatePath("bulk-set-" + hex.EncodeToString(hash[:8]) + ".progress")
+	if err != nil {
+		return nil, err
+	}
+
+	progress := &progress{path: path, done: map[string]bool{}}
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return progress, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		progress.done[strings.TrimSpace(scanner.Text())] = true
+	}
+	return progress, scanner.Err()
+}
+
+func (p *progress) add(pageID string) error {
+	file, err := os.OpenFile(p.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.WriteString(pageID + "\n")
+	return err
+}
+
+// remove throws the progress away once every page has been updated.
+func (p *progress) remove() error {
+	if err := os.Remove(p.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+package capture
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/jomei/notionapi"
+	"gopkg.in/yaml.v2"
+
+	"github.com/crockeo/notion-cli/commands"
+	"github.com/crockeo/notion-cli/config"
+	"github.com/crockeo/notion-cli/database"
+	"github.com/crockeo/notion-cli/errors"
+)
+
+// BatchRow is a single item to capture from a -from file, or an import.
+// Line is where the item starts, so that errors can point at it.
+type BatchRow struct {
+	Line     int
+	PropInfo *PropInfo
+}
+
+// captureBatch captures every item in a file.
+// every row is validated before anything is created
+// so that a typo on the last line doesn't leave us
+// with half of a backlog in Notion.
+func captureBatch(config *config.Config, client *notionapi.Client, template *config.CaptureTemplate, path string, format string, concurrency int) error {
+	databaseChan, errChan := database.Get(config, client)
+
+	rows, err := readBatch(path, format)
+	if err != nil {
+		return err
+	}
+
+	database, err := database.Join(databaseChan, errChan)
+	if err != nil {
+		return err
+	}
+	return CaptureRows(config, client, database, template, path, rows, concurrency)
+}
+
+// CaptureRows creates a page for every row,
+// after validating all of them the same way as -propinfo.
+// source is the file the rows came from, for error messages.
+func CaptureRows(config *config.Config, client *notionapi.Client, database *notionapi.Database, template *config.CaptureTemplate, source string, rows []BatchRow, concurrency int) error {
+	var err error
+	requests := make([]*notionapi.PageCreateRequest, len(rows))
+	failed := 0
+	for i, row := range rows {
+		requests[i], err = BuildRequest(config, database, template, row.PropInfo)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s:%d: %s\n", source, row.Line, err.Error())
+			failed++
+		}
+	}
+	if failed > 0 {
+		return errors.New(errors.KindValidation, "%d of %d rows failed validation, nothing was created", failed, len(rows))
+	}
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	// every row's error is kept, so that we can exit
+	// with the kind of the first row that failed
+	errs := make([]error, len(requests))
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	sem := make(chan struct{}, concurrency)
+	for i, request := range requests {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, row BatchRow, request *notionapi.PageCreateRequest) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			_, err := commands.CreatePage(client, request)
+			if err != nil {
+				lock.Lock()
+				fmt.Fprintf(os.Stderr, "%s:%d: %s\n", source, row.Line, err.Error())
+				errs[i] = err
+				failed++
+				lock.Unlock()
+			}
+		}(i, rows[i], request)
+	}
+	wg.Wait()
+
+	if commands.DryRun {
+		fmt.Fprintf(os.Stderr, "would create %d pages\n", len(rows))
+		return nil
+	}
+	fmt.Printf("created %d of %d pages\n", len(rows)-failed, len(rows))
+	for i, err := range errs {
+		if err != nil {
+			return errors.Wrap(errors.KindOf(err), err, "failed to create %d of %d pages, the first at %s:%d", failed, len(rows), source, rows[i].Line)
+		}
+	}
+	return nil
+}
+
+// BuildRequest validates propInfo the same way as -propinfo,
+// fills in the defaults, and builds the request to create its page.
+func BuildRequest(config *config.Config, db *notionapi.Database, template *config.CaptureTemplate, propInfo *PropInfo) (*notionapi.PageCreateRequest, error) {
+	if propInfo == nil {
+		propInfo = &PropInfo{}
+	}
+	title, err := getTitle(db, propInfo, false)
+	if err != nil {
+		return nil, err
+	}
+
+	properties, err := getPropInfoProperties(db, notionapi.Properties{}, propInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultProperties, err := getDefaultProperties(db, properties, config)
+	if err != nil {
+		return nil, err
+	}
+	for propName, property := range defaultProperties {
+		properties[propName] = property
+	}
+
+	var contents []byte
+	if propInfo.Body != nil {
+		contents = []byte(*propInfo.Body)
+	} else {
+		contents = renderTemplateBody(template, title)
+	}
+
+	return buildRequest(db, properties, contents)
+}
+
+func readBatch(path string, format string) ([]BatchRow, error) {
+	var reader io.Reader
+	if path == "-" {
+		reader = os.Stdin
+	} else {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(path), ".")
+	}
+
+	switch format {
+	case "jsonl", "json", "":
+		return readJSONLines(reader, path)
+	case "csv":
+		return readCSV(reader)
+	case "yaml", "yml":
+		return readYAML(reader)
+	}
+	return nil, errors.New(errors.KindUsage, "unknown -from format '%s'", format)
+}
+
+// readJSONLines reports every line that isn't valid JSON
+// before failing, the same way CaptureRows reports invalid rows.
+func readJSONLines(reader io.Reader, source string) ([]BatchRow, error) {
+	rows := []BatchRow{}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	failed := 0
+	for scanner.Scan() {
+		line++
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		propInfo := &PropInfo{}
+		if err := json.Unmarshal(scanner.Bytes(), propInfo); err != nil {
+			fmt.Fprintf(os.Stderr, "%s:%d: %s\n", source, line, err.Error())
+			failed++
+			continue
+		}
+		rows = append(rows, BatchRow{Line: line, PropInfo: propInfo})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if failed > 0 {
+		return nil, errors.New(errors.KindValidation, "%d of %d rows aren't valid JSON, nothing was created", failed, failed+len(rows))
+	}
+	return rows, nil
+}
+
+// readCSV treats the header as a list of property names,
+// except for a "body" column which holds the Markdown body.
+// empty cells are left out so that defaults can fill them in.
+func readCSV(reader io.Reader) ([]BatchRow, error) {
+	csvReader := csv.NewReader(reader)
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []BatchRow{}
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := csvReader.FieldPos(0)
+
+		propInfo := &PropInfo{Properties: map[string]string{}}
+		for i, value := range record {
+			if value == "" {
+				continue
+			}
+			if header[i] == "body" {
+				body := value
+				propInfo.Body = &body
+			} else {
+				propInfo.Properties[header[i]] = value
+			}
+		}
+		rows = append(rows, BatchRow{Line: line, PropInfo: propInfo})
+	}
+	return rows, nil
+}
+
+var yamlItemRegexp = regexp.MustCompile(`^-(\s|$)`)
+
+// readYAML reads a top-level list of PropInfos.
+// yaml.v2 doesn't tell us where items start,
+// so we find the top-level "- " markers ourselves
+// and fall back to item numbers if the layout is unusual.
+func readYAML(reader io.Reader) ([]BatchRow, error) {
+	contents, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	propInfos := []*PropInfo{}
+	if err := yaml.Unmarshal(contents, &propInfos); err != nil {
+		return nil, err
+	}
+
+	lines := []int{}
+	for i, line := range strings.Split(string(contents), "\n") {
+		if yamlItemRegexp.MatchString(line) {
+			lines = append(lines, i+1)
+		}
+	}
+	if len(lines) != len(propInfos) {
+		lines = make([]int, len(propInfos))
+		for i := range lines {
+			lines[i] = i + 1
+		}
+	}
+
+	rows := make([]BatchRow, len(propInfos))
+	for i, propInfo := range propInfos {
+		rows[i] = BatchRow{Line: lines[i], PropInfo: propInfo}
+	}
+	return rows, nil
+}
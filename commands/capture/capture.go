@@ -1,15 +1,11 @@
 package capture
 
 import (
-	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
-	"io"
-	"io/ioutil"
-	"os"
-	"os/exec"
 	"reflect"
+	"strings"
 
 	"github.com/jomei/notionapi"
 	"github.com/manifoldco/promptui"
@@ -17,28 +13,74 @@ import (
 	"github.com/crockeo/notion-cli/commands"
 	"github.com/crockeo/notion-cli/config"
 	"github.com/crockeo/notion-cli/database"
+	"github.com/crockeo/notion-cli/errors"
 	"github.com/crockeo/notion-cli/markdown"
 	"github.com/crockeo/notion-cli/parse"
 	"github.com/crockeo/notion-cli/prompt"
 )
 
 type PropInfo struct {
-	Title      *string           `json:"title,omitempty"`
-	Body       *string           `json:"body,omitempty"`
-	Properties map[string]string `json:"properties,omitempty"`
+	Title      *string           `json:"title,omitempty" yaml:"title,omitempty"`
+	Body       *string           `json:"body,omitempty" yaml:"body,omitempty"`
+	Properties map[string]string `json:"properties,omitempty" yaml:"properties,omitempty"`
 }
 
-func Capture(config *config.Config, client *notionapi.Client, args []string) {
-	interactive := flag.Bool("interactive", true, "Controls if notion-cli prompts for property values when not provided.")
-	propInfoStr := flag.String("propinfo", "", "Additional property info to in JSON format.")
-	flag.CommandLine.Parse(args)
+var (
+	flags        = flag.NewFlagSet("capture", flag.ContinueOnError)
+	interactive  = flags.Bool("interactive", true, "Controls if notion-cli prompts for property values when not provided.")
+	propInfoStr  = flags.String("propinfo", "", "Additional property info to in JSON format.")
+	templateName = flags.String("template", "", "Name of a template from capture.templates to capture with.")
+	from         = flags.String("from", "", "Capture every item in a .jsonl, .csv or .yaml file, or - for stdin.")
+	fromFormat   = flags.String("format", "", "Format of the -from file (jsonl, csv, yaml). Defaults to the file extension.")
+	concurrency  = flags.Int("concurrency", 3, "Number of pages created at once with -from.")
+	onDuplicate  = flags.String("on-duplicate", "", "What to do when a similar open item exists: skip, create, fail, open, or merge. Prompts when interactive.")
+)
+
+var Command = &commands.Command{
+	Name:    "capture",
+	Summary: "Interactively capture a task from the terminal.",
+	Usage:   "[flags] [quick capture text]",
+	Examples: []string{
+		"capture",
+		"capture 'Renew passport #admin !high @next tuesday +Personal'",
+		"capture -template bug",
+		"capture -interactive=false -propinfo '{\"properties\": {\"Name\": \"Water plants\"}}'",
+		"capture -from tasks.csv",
+	},
+	Flags:    flags,
+	Complete: commands.CompleteSigils,
+	Run:      Capture,
+}
+
+func Capture(config *config.Config, client *notionapi.Client, args []string) error {
+	template, err := getTemplate(config, *templateName)
+	if err != nil {
+		return err
+	}
+	config = applyTemplate(config, template)
+
+	if err := validateDuplicateAction(*onDuplicate); err != nil {
+		return err
+	}
+
+	if *from != "" {
+		return captureBatch(config, client, template, *from, *fromFormat, *concurrency)
+	}
+
+	// quick capture lines are meant to be one-shot,
+	// so they only prompt when explicitly asked to
+	quickLine := strings.Join(args, " ")
+	if quickLine != "" && !isFlagSet("interactive") {
+		*interactive = false
+	}
 
 	var propInfo *PropInfo
 	if len(*propInfoStr) > 0 {
 		propInfo = &PropInfo{}
 		err := json.Unmarshal([]byte(*propInfoStr), propInfo)
-		commands.Guard(err)
-		args = args[1:]
+		if err != nil {
+			return errors.Wrap(errors.KindUsage, err, "-propinfo is not valid JSON")
+		}
 	}
 
 	// pulling the database takes a moment
@@ -46,47 +88,136 @@ func Capture(config *config.Config, client *notionapi.Client, args []string) {
 	// behind a prompt for the page title
 	databaseChan, errChan := database.Get(config, client)
 
-	title, err := getTitle(propInfo, *interactive)
-	commands.Guard(err)
+	var title string
+	// with an editor, the title is asked for along with everything else
+	useEditor := *interactive && commands.HasEditor()
+	if quickLine == "" && *interactive && !useEditor && !mayHaveTitle(propInfo) {
+		titlePrompt := promptui.Prompt{Label: "Title"}
+		title, err = titlePrompt.Run()
+		if err != nil {
+			return err
+		}
+	}
 
 	database, err := database.Join(databaseChan, errChan)
-	commands.Guard(err)
+	if err != nil {
+		return err
+	}
+
+	if quickLine != "" {
+		title, propInfo, err = getQuickPropInfo(client, database, propInfo, quickLine, config)
+		if err != nil {
+			return err
+		}
+	} else {
+		if propInfo == nil {
+			propInfo = &PropInfo{}
+		}
+		if title != "" {
+			propInfo.Title = &title
+		}
+		if useEditor {
+			title, err = resolveTitle(database, propInfo)
+		} else {
+			title, err = getTitle(database, propInfo, *interactive)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if *onDuplicate != duplicateCreate && (*interactive || *onDuplicate != "") {
+		duplicate, err := findDuplicate(config, client, database, title)
+		if err != nil {
+			return err
+		}
+		if duplicate != nil {
+			action, err := getDuplicateAction(duplicate, *onDuplicate, *interactive)
+			if err != nil {
+				return err
+			}
+
+			switch action {
+			case duplicateSkip:
+				fmt.Println("skipping, a similar item already exists:", duplicate.URL)
+				return nil
+			case duplicateOpen:
+				return commands.OpenURL(duplicate.URL)
+			case duplicateMerge:
+				contents, err := getBody(template, title, propInfo, *interactive)
+				if err != nil {
+					return err
+				}
+				return mergeDuplicate(client, duplicate, contents)
+			}
+		}
+	}
 
 	properties := map[string]notionapi.Property{}
 	if propInfo != nil {
 		propInfoProperties, err := getPropInfoProperties(database, properties, propInfo)
-		commands.Guard(err)
+		if err != nil {
+			return err
+		}
 		for propName, property := range propInfoProperties {
 			properties[propName] = property
 		}
 	}
 
 	defaultProperties, err := getDefaultProperties(database, properties, config)
-	commands.Guard(err)
+	if err != nil {
+		return err
+	}
 	for propName, property := range defaultProperties {
 		properties[propName] = property
 	}
 
-	if *interactive {
-		interactiveProps, err := getInteractiveProperties(database, properties, title, config)
-		commands.Guard(err)
-		for propName, property := range interactiveProps {
+	var contents []byte
+	if useEditor {
+		// with an editor, properties and body are filled in together
+		values := editorValues(database, title, propInfo)
+		editedProps, editedContents, err := editProperties(database, values, config, template, renderTemplateBody(template, title))
+		if err != nil {
+			return err
+		}
+		for propName, property := range editedProps {
 			properties[propName] = property
 		}
+		contents = editedContents
+	} else {
+		if *interactive {
+			interactiveProps, err := getInteractiveProperties(database, properties, title, config, template)
+			if err != nil {
+				return err
+			}
+			for propName, property := range interactiveProps {
+				properties[propName] = property
+			}
+		}
+
+		contents, err = getBody(template, title, propInfo, *interactive)
+		if err != nil {
+			return err
+		}
 	}
 
-	contents := []byte{}
-	if *interactive {
-		contents, err = getInteractiveBody()
-		commands.Guard(err)
-	} else if propInfo != nil && propInfo.Body != nil {
-		contents = []byte(*propInfo.Body)
+	request, err := buildRequest(database, properties, contents)
+	if err != nil {
+		return err
 	}
 
+	_, err = commands.CreatePage(client, request)
+	return err
+}
+
+func buildRequest(database *notionapi.Database, properties notionapi.Properties, contents []byte) (*notionapi.PageCreateRequest, error) {
 	children := []notionapi.Block{}
 	if len(contents) > 0 {
+		var err error
 		children, err = markdown.ToBlocks(contents)
-		commands.Guard(err)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// we must remove null values from the list
@@ -98,51 +229,89 @@ func Capture(config *config.Config, client *notionapi.Client, args []string) {
 		}
 	}
 
-	_, err = client.Page.Create(
-		context.Background(),
-		&notionapi.PageCreateRequest{
-			Parent: notionapi.Parent{
-				Type:       notionapi.ParentTypeDatabaseID,
-				DatabaseID: notionapi.DatabaseID(database.ID),
-			},
-			Properties: properties,
-			Children:   children,
+	return &notionapi.PageCreateRequest{
+		Parent: notionapi.Parent{
+			Type:       notionapi.ParentTypeDatabaseID,
+			DatabaseID: notionapi.DatabaseID(database.ID),
 		},
-	)
-	commands.Guard(err)
+		Properties: properties,
+		Children:   children,
+	}, nil
 }
 
-func getTitle(propInfo *PropInfo, interactive bool) (string, error) {
-	if propInfo != nil && propInfo.Title != nil {
-		title, ok := propInfo.Properties[*propInfo.Title]
-		if ok {
-			return title, nil
-		}
+// mayHaveTitle is whether propInfo could hold the title,
+// which we can only be sure of once we know the title property's name.
+func mayHaveTitle(propInfo *PropInfo) bool {
+	return propInfo != nil && (propInfo.Title != nil && *propInfo.Title != "" || len(propInfo.Properties) > 0)
+}
+
+// getTitle finds the title in propInfo, prompting for it when interactive,
+// and makes sure that it's set on the database's title property.
+func getTitle(db *notionapi.Database, propInfo *PropInfo, interactive bool) (string, error) {
+	title, err := resolveTitle(db, propInfo)
+	if err != nil || title != "" {
+		return title, err
 	}
 
 	if interactive {
 		titlePrompt := promptui.Prompt{Label: "Title"}
-		return titlePrompt.Run()
+		title, err := titlePrompt.Run()
+		if err != nil {
+			return "", err
+		}
+		propInfo.Title = &title
+		return resolveTitle(db, propInfo)
 	}
 
-	return "", fmt.Errorf("failed to get a title! :(")
+	titleProp, _ := database.TitleProperty(db)
+	return "", errors.New(errors.KindValidation, "missing a title, set \"title\" or properties.%s", titleProp)
+}
+
+// resolveTitle reads the title from either PropInfo.Title
+// or the database's title property in PropInfo.Properties,
+// and copies it into the latter, which is what the request is built from.
+// it returns an empty title when neither is set.
+func resolveTitle(db *notionapi.Database, propInfo *PropInfo) (string, error) {
+	titleProp, ok := database.TitleProperty(db)
+	if !ok {
+		return "", errors.New(errors.KindConfig, "the database doesn't have a title property")
+	}
+
+	title := ""
+	if propInfo.Title != nil {
+		title = *propInfo.Title
+	}
+	if propTitle := propInfo.Properties[titleProp]; propTitle != "" {
+		if title != "" && title != propTitle {
+			return "", errors.New(errors.KindValidation, "\"title\" is '%s' but properties.%s is '%s'", title, titleProp, propTitle)
+		}
+		title = propTitle
+	}
+
+	if title != "" {
+		if propInfo.Properties == nil {
+			propInfo.Properties = map[string]string{}
+		}
+		propInfo.Properties[titleProp] = title
+	}
+	return title, nil
 }
 
 func getPropInfoProperties(database *notionapi.Database, properties notionapi.Properties, propInfo *PropInfo) (notionapi.Properties, error) {
 	if propInfo == nil {
-		return nil, fmt.Errorf("passed a null propInfo :(")
+		return nil, errors.New(errors.KindValidation, "passed a null propInfo :(")
 	}
 
 	propInfoProperties := notionapi.Properties{}
 	for propName, propValue := range propInfo.Properties {
 		propConfig, ok := database.Properties[propName]
 		if !ok {
-			return nil, fmt.Errorf("provided JSON arg %s does not exist in the database", propName)
+			return nil, errors.New(errors.KindValidation, "provided JSON arg %s does not exist in the database", propName)
 		}
 
 		property, err := parse.Property(propName, propConfig, propValue)
 		if err != nil {
-			return nil, err
+			return nil, errors.Wrap(errors.KindValidation, err, "invalid value for '%s'", propName)
 		}
 
 		propInfoProperties[propName] = property
@@ -159,12 +328,12 @@ func getDefaultProperties(database *notionapi.Database, properties notionapi.Pro
 
 		propConfig, ok := database.Properties[propName]
 		if !ok {
-			return nil, fmt.Errorf("Config.Capture.Defaults contains propName which doesn't exist '%s'", propName)
+			return nil, errors.New(errors.KindConfig, "Config.Capture.Defaults contains propName which doesn't exist '%s'", propName)
 		}
 
 		property, err := parse.Property(propName, propConfig, propValue)
 		if err != nil {
-			return nil, err
+			return nil, errors.Wrap(errors.KindConfig, err, "invalid default for '%s'", propName)
 		}
 
 		defaultProperties[propName] = property
@@ -172,16 +341,25 @@ func getDefaultProperties(database *notionapi.Database, properties notionapi.Pro
 	return defaultProperties, nil
 }
 
-func getInteractiveProperties(database *notionapi.Database, properties notionapi.Properties, title string, config *config.Config) (notionapi.Properties, error) {
+func getInteractiveProperties(database *notionapi.Database, properties notionapi.Properties, title string, config *config.Config, template *config.CaptureTemplate) (notionapi.Properties, error) {
+	seen := map[string]bool{}
+	if template != nil {
+		for _, propName := range template.Skip {
+			seen[propName] = true
+		}
+	}
+
 	order := []string{}
 	for _, propName := range config.Capture.Order {
-		if _, ok := properties[propName]; !ok {
+		if _, ok := properties[propName]; !ok && !seen[propName] {
 			order = append(order, propName)
+			seen[propName] = true
 		}
 	}
 	for propName := range database.Properties {
-		if _, ok := properties[propName]; !ok {
+		if _, ok := properties[propName]; !ok && !seen[propName] {
 			order = append(order, propName)
+			seen[propName] = true
 		}
 	}
 
@@ -193,7 +371,7 @@ func getInteractiveProperties(database *notionapi.Database, properties notionapi
 
 		propConfig, ok := database.Properties[propName]
 		if !ok {
-			return nil, fmt.Errorf("Capture.Capture.Order contains propName which doesn't exist '%s'", propName)
+			return nil, errors.New(errors.KindConfig, "Capture.Capture.Order contains propName which doesn't exist '%s'", propName)
 		}
 		if _, ok := propConfig.(*notionapi.FormulaPropertyConfig); ok {
 			// we can't populate anything for a formula
@@ -202,49 +380,31 @@ func getInteractiveProperties(database *notionapi.Database, properties notionapi
 		}
 
 		property, err := prompt.Property(title, propName, propConfig)
-		commands.Guard(err)
+		if err != nil {
+			return nil, err
+		}
 
 		interactiveProperties[propName] = property
 	}
 	return interactiveProperties, nil
 }
 
-func getInteractiveBody() ([]byte, error) {
-	contents := []byte{}
-
-	editor, ok := os.LookupEnv("EDITOR")
-	if !ok {
-		fmt.Println("Body:")
-		body := make([]byte, 512)
-		for {
-			n, err := os.Stdin.Read(body)
-			if err != nil && err != io.EOF {
-				return nil, err
-			}
-			contents = append(contents, body[:n]...)
-			if err == io.EOF {
-				break
-			}
-		}
-	} else {
-		file, err := ioutil.TempFile("", "*.md")
-		if err != nil {
-			return nil, err
-		}
-		defer os.Remove(file.Name())
-
-		cmd := exec.Command(editor, file.Name())
-		cmd.Stdin = os.Stdin
-		cmd.Stdout = os.Stdout
-		if err := cmd.Run(); err != nil {
-			return nil, err
-		}
-
-		contents, err = os.ReadFile(file.Name())
-		if err != nil {
-			return nil, err
-		}
+func getBody(template *config.CaptureTemplate, title string, propInfo *PropInfo, interactive bool) ([]byte, error) {
+	contents := renderTemplateBody(template, title)
+	if interactive {
+		return commands.EditBody(contents)
+	} else if propInfo != nil && propInfo.Body != nil {
+		return []byte(*propInfo.Body), nil
 	}
-
 	return contents, nil
 }
+
+func isFlagSet(name string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
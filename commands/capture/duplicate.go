@@ -0,0 +1,191 @@
+package capture
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/jomei/notionapi"
+	"github.com/manifoldco/promptui"
+
+	"github.com/crockeo/notion-cli/commands"
+	"github.com/crockeo/notion-cli/config"
+	"github.com/crockeo/notion-cli/database"
+	"github.com/crockeo/notion-cli/errors"
+)
+
+const defaultDuplicateThreshold = 0.8
+
+const (
+	duplicateOpen   = "open"
+	duplicateMerge  = "merge"
+	duplicateCreate = "create"
+	duplicateSkip   = "skip"
+	duplicateFail   = "fail"
+)
+
+var duplicateActions = []string{duplicateSkip, duplicateCreate, duplicateFail, duplicateOpen, duplicateMerge}
+
+// validateDuplicateAction checks -on-duplicate before we do any work,
+// rather than only once a duplicate turns up.
+func validateDuplicateAction(onDuplicate string) error {
+	if onDuplicate == "" {
+		return nil
+	}
+	for _, action := range duplicateActions {
+		if onDuplicate == action {
+			return nil
+		}
+	}
+	return errors.New(errors.KindUsage, "-on-duplicate must be one of %s, got '%s'", strings.Join(duplicateActions, ", "), onDuplicate)
+}
+
+// findDuplicate looks for an open page whose title is close enough to title
+// that we probably captured the same thing before (e.g. from another machine).
+// we only compare against the open pages whose titles contain the longest word of title,
+// so that the server does the narrowing rather than us pulling every open page.
+func findDuplicate(config *config.Config, client *notionapi.Client, db *notionapi.Database, title string) (*notionapi.Page, error) {
+	threshold := config.Capture.DuplicateThreshold
+	if threshold == 0 {
+		threshold = defaultDuplicateThreshold
+	}
+
+	normalizedTitle := normalizeTitle(title)
+	titleProp, ok := database.TitleProperty(db)
+	longest := longestWord(normalizedTitle)
+	if !ok || longest == "" {
+		return nil, nil
+	}
+
+	filters := []notionapi.PropertyFilter{{
+		Property: titleProp,
+		Text:     &notionapi.TextFilterCondition{Contains: longest},
+	}}
+	if openFilter := database.OpenFilter(config); openFilter != nil {
+		filters = append(filters, *openFilter)
+	}
+	pages, err := database.QueryAll(config, client, &notionapi.DatabaseQueryRequest{
+		CompoundFilter: &notionapi.CompoundFilter{notionapi.FilterOperatorAND: filters},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var best *notionapi.Page
+	bestScore := 0.0
+	for i := range pages {
+		score := similarity(normalizedTitle, normalizeTitle(database.PageTitle(&pages[i])))
+		if score >= threshold && score > bestScore {
+			best = &pages[i]
+			bestScore = score
+		}
+	}
+	return best, nil
+}
+
+// getDuplicateAction decides what to do about a duplicate:
+// onDuplicate wins when it's provided,
+// otherwise we ask when interactive and create when not.
+func getDuplicateAction(duplicate *notionapi.Page, onDuplicate string, interactive bool) (string, error) {
+	switch onDuplicate {
+	case duplicateFail:
+		return "", errors.New(errors.KindValidation, "'%s' already exists: %s", database.PageTitle(duplicate), duplicate.URL)
+	case "":
+	default:
+		return onDuplicate, nil
+	}
+
+	if !interactive {
+		return duplicateCreate, nil
+	}
+
+	actions := []string{duplicateOpen, duplicateMerge, duplicateCreate}
+	prompt := promptui.Select{
+		Label: fmt.Sprintf("Similar item exists: '%s'", database.PageTitle(duplicate)),
+		Items: []string{
+			"Open the existing item",
+			"Append the new body to the existing item",
+			"Create anyway",
+		},
+	}
+	i, _, err := prompt.Run()
+	if err != nil {
+		return "", err
+	}
+	return actions[i], nil
+}
+
+// mergeDuplicate appends the body of what we would have captured
+// to the page which already exists.
+func mergeDuplicate(client *notionapi.Client, duplicate *notionapi.Page, contents []byte) error {
+	request, err := buildRequest(&notionapi.Database{}, notionapi.Properties{}, contents)
+	if err != nil {
+		return err
+	}
+	if len(request.Children) == 0 {
+		return nil
+	}
+	return commands.AppendBlocks(client, notionapi.BlockID(duplicate.ID), request.Children)
+}
+
+func longestWord(normalizedTitle string) string {
+	longest := ""
+	for _, word := range strings.Fields(normalizedTitle) {
+		if len([]rune(word)) > len([]rune(longest)) {
+			longest = word
+		}
+	}
+	return longest
+}
+
+func normalizeTitle(title string) string {
+	fields := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+// similarity scores two strings between 0 (nothing in common)
+// and 1 (identical), based on their edit distance.
+func similarity(a, b string) float64 {
+	ar := []rune(a)
+	br := []rune(b)
+	longest := len(ar)
+	if len(br) > longest {
+		longest = len(br)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ar, br))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func min(values ...int) int {
+	result := values[0]
+	for _, value := range values[1:] {
+		if value < result {
+			result = value
+		}
+	}
+	return result
+}
@@ -0,0 +1,188 @@
+package capture
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jomei/notionapi"
+	"gopkg.in/yaml.v2"
+
+	"github.com/crockeo/notion-cli/commands"
+	"github.com/crockeo/notion-cli/config"
+	"github.com/crockeo/notion-cli/database"
+	"github.com/crockeo/notion-cli/errors"
+	"github.com/crockeo/notion-cli/markdown"
+	"github.com/crockeo/notion-cli/parse"
+)
+
+const errorComment = "# error: "
+
+// editProperties opens $EDITOR once for both the properties and the body.
+// the properties go in YAML frontmatter, one line per property,
+// with the defaults and options that apply to it as comments.
+// when something doesn't parse we open the editor again,
+// with what went wrong at the top of the frontmatter.
+func editProperties(db *notionapi.Database, values map[string]string, config *config.Config, template *config.CaptureTemplate, body []byte) (notionapi.Properties, []byte, error) {
+	order := editorOrder(db, config, template)
+	contents, err := renderFrontmatter(db, order, values, config)
+	if err != nil {
+		return nil, nil, err
+	}
+	contents = markdown.JoinFrontmatter(contents, body)
+
+	for {
+		contents, err = commands.EditBody(contents)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(bytes.TrimSpace(contents)) == 0 {
+			return nil, nil, errors.New(errors.KindUserAbort, "the capture was emptied, aborting")
+		}
+
+		frontmatter, body, ok := markdown.SplitFrontmatter(contents)
+		if !ok {
+			// no frontmatter means no properties, rather than a mistake
+			return notionapi.Properties{}, body, nil
+		}
+
+		properties, problems := parseFrontmatter(db, frontmatter)
+		if len(problems) == 0 {
+			return properties, body, nil
+		}
+		contents = markdown.JoinFrontmatter(inlineErrors(frontmatter, problems), body)
+	}
+}
+
+// editorOrder lists the properties we can parse,
+// title first and then in the capture order.
+func editorOrder(db *notionapi.Database, config *config.Config, template *config.CaptureTemplate) []string {
+	seen := map[string]bool{}
+	if template != nil {
+		for _, propName := range template.Skip {
+			seen[propName] = true
+		}
+	}
+
+	order := []string{}
+	add := func(propName string) {
+		propConfig, ok := db.Properties[propName]
+		if !ok || seen[propName] {
+			return
+		}
+		seen[propName] = true
+		// formulas, rollups and the like can't be written
+		if _, err := parse.Property(propName, propConfig, ""); err != nil {
+			if _, invalid := err.(*errors.ErrInvalidPropertyConfig); invalid {
+				return
+			}
+		}
+		order = append(order, propName)
+	}
+
+	if titleProperty, ok := database.TitleProperty(db); ok {
+		add(titleProperty)
+	}
+	for _, propName := range config.Capture.Order {
+		add(propName)
+	}
+	rest := []string{}
+	for propName := range db.Properties {
+		rest = append(rest, propName)
+	}
+	sort.Strings(rest)
+	for _, propName := range rest {
+		add(propName)
+	}
+	return order
+}
+
+func renderFrontmatter(db *notionapi.Database, order []string, values map[string]string, config *config.Config) ([]byte, error) {
+	var builder strings.Builder
+	builder.WriteString("# fill in properties below, empty ones use their default or are left unset\n")
+	for _, propName := range order {
+		propConfig := db.Properties[propName]
+
+		comment := fmt.Sprintf("# %s (%s)", propName, propConfig.GetType())
+		if options := commands.OptionNames(propConfig); len(options) > 0 {
+			comment += ": " + strings.Join(options, ", ")
+		}
+		builder.WriteString(comment + "\n")
+		if defaultValue, ok := config.Capture.Defaults[propName]; ok {
+			builder.WriteString("# default: " + defaultValue + "\n")
+		}
+
+		line, err := yaml.Marshal(yaml.MapSlice{{Key: propName, Value: values[propName]}})
+		if err != nil {
+			return nil, err
+		}
+		// an empty value reads better as Due: than as Due: ""
+		if values[propName] == "" {
+			line = bytes.Replace(line, []byte(`: ""`), []byte(":"), 1)
+		}
+		builder.Write(line)
+	}
+	return []byte(builder.String()), nil
+}
+
+// parseFrontmatter parses every property in frontmatter,
+// and returns everything that's wrong with it rather than just the first thing.
+func parseFrontmatter(db *notionapi.Database, frontmatter []byte) (notionapi.Properties, []string) {
+	raw := yaml.MapSlice{}
+	if err := yaml.Unmarshal(frontmatter, &raw); err != nil {
+		return nil, []string{err.Error()}
+	}
+
+	properties := notionapi.Properties{}
+	problems := []string{}
+	for _, item := range raw {
+		propName := fmt.Sprint(item.Key)
+		propValue := markdown.FrontmatterString(item.Value)
+		if propValue == "" {
+			continue
+		}
+
+		propConfig, ok := db.Properties[propName]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s does not exist in the database", propName))
+			continue
+		}
+		property, err := parse.Property(propName, propConfig, propValue)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("invalid value for '%s': %s", propName, err.Error()))
+			continue
+		}
+		properties[propName] = property
+	}
+	return properties, problems
+}
+
+// inlineErrors replaces the errors from the last attempt with problems.
+func inlineErrors(frontmatter []byte, problems []string) []byte {
+	lines := []string{}
+	for _, problem := range problems {
+		lines = append(lines, errorComment+strings.ReplaceAll(problem, "\n", " "))
+	}
+	for _, line := range strings.SplitAfter(string(frontmatter), "\n") {
+		if line != "" && !strings.HasPrefix(line, errorComment) {
+			lines = append(lines, strings.TrimSuffix(line, "\n"))
+		}
+	}
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
+
+// editorValues fills in the values we already have,
+// so that they can be changed in the editor.
+func editorValues(db *notionapi.Database, title string, propInfo *PropInfo) map[string]string {
+	values := map[string]string{}
+	if propInfo != nil {
+		for propName, propValue := range propInfo.Properties {
+			values[propName] = propValue
+		}
+	}
+	if titleProperty, ok := database.TitleProperty(db); ok && title != "" {
+		values[titleProperty] = title
+	}
+	return values
+}
@@ -0,0 +1,98 @@
+package capture
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jomei/notionapi"
+
+	"github.com/crockeo/notion-cli/config"
+	"github.com/crockeo/notion-cli/database"
+	"github.com/crockeo/notion-cli/errors"
+	"github.com/crockeo/notion-cli/parse"
+	"github.com/crockeo/notion-cli/quick"
+)
+
+// getQuickPropInfo parses a quick capture line
+// into the same PropInfo we get from -propinfo,
+// so that both go through the same validation.
+// values from the line take precedence over -propinfo.
+func getQuickPropInfo(client *notionapi.Client, db *notionapi.Database, propInfo *PropInfo, line string, config *config.Config) (string, *PropInfo, error) {
+	result, err := quick.Parse(line, config.Capture.Sigils, db)
+	if err != nil {
+		return "", nil, err
+	}
+	if result.Title == "" {
+		return "", nil, errors.New(errors.KindValidation, "quick capture '%s' does not contain a title", line)
+	}
+
+	titleProp, ok := database.TitleProperty(db)
+	if !ok {
+		return "", nil, errors.New(errors.KindValidation, "database does not have a title property")
+	}
+
+	if propInfo == nil {
+		propInfo = &PropInfo{}
+	}
+	if propInfo.Properties == nil {
+		propInfo.Properties = map[string]string{}
+	}
+	for propName, propValue := range result.Values {
+		if propConfig, ok := db.Properties[propName].(*notionapi.RelationPropertyConfig); ok {
+			propValue, err = ResolveRelation(client, propConfig, propValue)
+			if err != nil {
+				return "", nil, err
+			}
+		}
+		propInfo.Properties[propName] = propValue
+	}
+	propInfo.Properties[titleProp] = result.Title
+
+	return result.Title, propInfo, nil
+}
+
+// ResolveRelation turns a comma separated list of page titles
+// into the page IDs that parse.ParseRelation expects.
+// values which are already IDs are passed through untouched.
+func ResolveRelation(client *notionapi.Client, propConfig *notionapi.RelationPropertyConfig, propValue string) (string, error) {
+	relatedDatabaseID := database.NormalizeID(string(propConfig.Relation.DatabaseID))
+
+	ids := []string{}
+	for _, name := range strings.Split(propValue, ",") {
+		if parse.IsObjectID(name) {
+			ids = append(ids, name)
+			continue
+		}
+
+		resp, err := client.Search.Do(
+			context.Background(),
+			&notionapi.SearchRequest{
+				Query: name,
+				Filter: map[string]string{
+					"property": "object",
+					"value":    "page",
+				},
+			},
+		)
+		if err != nil {
+			return "", err
+		}
+
+		found := false
+		for _, result := range resp.Results {
+			page, ok := result.(*notionapi.Page)
+			if !ok || database.NormalizeID(string(page.Parent.DatabaseID)) != relatedDatabaseID {
+				continue
+			}
+			if strings.EqualFold(database.PageTitle(page), name) {
+				ids = append(ids, string(page.ID))
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", errors.New(errors.KindNotFound, "could not find a page named '%s' to relate to", name)
+		}
+	}
+	return strings.Join(ids, ","), nil
+}
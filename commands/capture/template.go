@@ -0,0 +1,57 @@
+package capture
+
+import (
+	"strings"
+	"time"
+
+	"github.com/crockeo/notion-cli/config"
+	"github.com/crockeo/notion-cli/errors"
+)
+
+func getTemplate(config *config.Config, name string) (*config.CaptureTemplate, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	template, ok := config.Capture.Templates[name]
+	if !ok {
+		return nil, errors.New(errors.KindConfig, "Config.Capture.Templates does not contain template '%s'", name)
+	}
+	return &template, nil
+}
+
+// applyTemplate layers a template on top of config.Capture
+// so that the rest of capture doesn't need to know about templates:
+// template defaults win over config defaults,
+// and the template order comes before the config order.
+func applyTemplate(c *config.Config, template *config.CaptureTemplate) *config.Config {
+	if template == nil {
+		return c
+	}
+
+	templated := *c
+	templated.Capture.Defaults = map[string]string{}
+	for propName, propValue := range c.Capture.Defaults {
+		templated.Capture.Defaults[propName] = propValue
+	}
+	for propName, propValue := range template.Defaults {
+		templated.Capture.Defaults[propName] = propValue
+	}
+
+	templated.Capture.Order = append([]string{}, template.Order...)
+	templated.Capture.Order = append(templated.Capture.Order, c.Capture.Order...)
+
+	return &templated
+}
+
+func renderTemplateBody(template *config.CaptureTemplate, title string) []byte {
+	if template == nil {
+		return []byte{}
+	}
+
+	replacer := strings.NewReplacer(
+		"{{title}}", title,
+		"{{date}}", time.Now().Format("2006-01-02"),
+	)
+	return []byte(replacer.Replace(template.Body))
+}
@@ -0,0 +1,134 @@
+package capture
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jomei/notionapi"
+
+	"github.com/crockeo/notion-cli/config"
+	"github.com/crockeo/notion-cli/errors"
+)
+
+func testDatabase() *notionapi.Database {
+	return &notionapi.Database{
+		ID: "0123456789abcdef0123456789abcdef",
+		Properties: notionapi.PropertyConfigs{
+			"Name":  &notionapi.TitlePropertyConfig{Type: notionapi.PropertyConfigTypeTitle},
+			"Notes": &notionapi.RichTextPropertyConfig{Type: notionapi.PropertyConfigTypeRichText},
+		},
+	}
+}
+
+func stringPointer(s string) *string {
+	return &s
+}
+
+func TestResolveTitle(t *testing.T) {
+	noTitle := &notionapi.Database{
+		Properties: notionapi.PropertyConfigs{
+			"Notes": &notionapi.RichTextPropertyConfig{Type: notionapi.PropertyConfigTypeRichText},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		db       *notionapi.Database
+		propInfo *PropInfo
+		title    string
+		kind     errors.Kind
+	}{
+		{
+			name:     "title only",
+			db:       testDatabase(),
+			propInfo: &PropInfo{Title: stringPointer("Water plants")},
+			title:    "Water plants",
+		},
+		{
+			name:     "title property only",
+			db:       testDatabase(),
+			propInfo: &PropInfo{Properties: map[string]string{"Name": "Water plants"}},
+			title:    "Water plants",
+		},
+		{
+			name: "both and equal",
+			db:   testDatabase(),
+			propInfo: &PropInfo{
+				Title:      stringPointer("Water plants"),
+				Properties: map[string]string{"Name": "Water plants"},
+			},
+			title: "Water plants",
+		},
+		{
+			name: "both and different",
+			db:   testDatabase(),
+			propInfo: &PropInfo{
+				Title:      stringPointer("Water plants"),
+				Properties: map[string]string{"Name": "Feed the cat"},
+			},
+			kind: errors.KindValidation,
+		},
+		{
+			name:     "neither",
+			db:       testDatabase(),
+			propInfo: &PropInfo{Properties: map[string]string{"Notes": "in the morning"}},
+		},
+		{
+			name:     "no title property",
+			db:       noTitle,
+			propInfo: &PropInfo{Title: stringPointer("Water plants")},
+			kind:     errors.KindConfig,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			title, err := resolveTitle(test.db, test.propInfo)
+			if test.kind != "" {
+				if err == nil {
+					t.Fatalf("expected a %s error, got the title '%s'", test.kind, title)
+				}
+				if kind := errors.KindOf(err); kind != test.kind {
+					t.Fatalf("expected a %s error, got %s: %s", test.kind, kind, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if title != test.title {
+				t.Fatalf("expected the title '%s', got '%s'", test.title, title)
+			}
+			if test.title != "" && test.propInfo.Properties["Name"] != test.title {
+				t.Fatalf("expected the title to be copied into properties.Name, got '%s'", test.propInfo.Properties["Name"])
+			}
+		})
+	}
+}
+
+func TestBuildRequestMissingTitle(t *testing.T) {
+	_, err := BuildRequest(&config.Config{}, testDatabase(), nil, &PropInfo{})
+	if err == nil {
+		t.Fatal("expected an error without a title")
+	}
+	if kind := errors.KindOf(err); kind != errors.KindValidation {
+		t.Fatalf("expected a %s error, got %s: %s", errors.KindValidation, kind, err)
+	}
+	if !strings.Contains(err.Error(), "missing a title") || !strings.Contains(err.Error(), "properties.Name") {
+		t.Fatalf("expected the error to point at title and properties.Name, got: %s", err)
+	}
+}
+
+func TestBuildRequestCopiesTitle(t *testing.T) {
+	request, err := BuildRequest(&config.Config{}, testDatabase(), nil, &PropInfo{Title: stringPointer("Water plants")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	title, ok := request.Properties["Name"].(*notionapi.TitleProperty)
+	if !ok {
+		t.Fatalf("expected a title property in Name, got %#v", request.Properties["Name"])
+	}
+	if len(title.Title) != 1 || title.Title[0].Text.Content != "Water plants" {
+		t.Fatalf("expected the title 'Water plants', got %#v", title.Title)
+	}
+}
@@ -0,0 +1,209 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jomei/notionapi"
+
+	"github.com/crockeo/notion-cli/config"
+	"github.com/crockeo/notion-cli/errors"
+)
+
+// Command is a single notion-cli subcommand.
+// Flags belong to the command, so that `notion-cli <command> --help`
+// and `notion-cli help <command>` can describe them.
+type Command struct {
+	Name     string
+	Summary  string
+	Usage    string
+	Examples []string
+	Flags    *flag.FlagSet
+
+	// Complete optionally suggests positional arguments for shell completion,
+	// given the cached schema and the word being completed.
+	// see CompleteProperties and CompleteSigils.
+	Complete func(config *config.Config, database *notionapi.Database, current string) []string
+
+	// ConfigOptional commands still run when there's no .notion-cli,
+	// in which case Run receives a nil config and client.
+	ConfigOptional bool
+
+	// Run receives the positional arguments left over
+	// after Flags has been parsed.
+	Run func(config *config.Config, client *notionapi.Client, args []string) error
+}
+
+var registry = []*Command{}
+
+// Register adds commands to the list that Main dispatches to.
+// help is listed in the order commands are registered.
+func Register(commands ...*Command) {
+	registry = append(registry, commands...)
+}
+
+// Lookup finds a registered command by name.
+func Lookup(name string) (*Command, bool) {
+	for _, command := range registry {
+		if command.Name == name {
+			return command, true
+		}
+	}
+	return nil, false
+}
+
+// Registered returns every registered command, in order.
+func Registered() []*Command {
+	return registry
+}
+
+// registerGlobalFlags adds the flags every command accepts to flags.
+// they're accepted both before and after the command name.
+func registerGlobalFlags(flags *flag.FlagSet) {
+	flags.BoolVar(&DryRun, "dry-run", DryRun, "Print the API requests which would be sent instead of sending them.")
+	flags.StringVar(&Output, "output", Output, "Output format for errors: text or json.")
+}
+
+// Main parses global flags, finds the command named by args,
+// and runs it. it never returns.
+func Main(args []string) {
+	globalFlags := flag.NewFlagSet("notion-cli", flag.ContinueOnError)
+	globalFlags.SetOutput(io.Discard)
+	registerGlobalFlags(globalFlags)
+	if err := globalFlags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			PrintHelp()
+			os.Exit(0)
+		}
+		Guard(errors.Wrap(errors.KindUsage, err, ""))
+	}
+
+	args = globalFlags.Args()
+	if len(args) == 0 {
+		PrintHelp()
+		os.Exit(errors.KindUsage.ExitCode())
+	}
+
+	if args[0] == "help" {
+		if len(args) == 1 {
+			PrintHelp()
+			os.Exit(0)
+		}
+		command, ok := Lookup(args[1])
+		if !ok {
+			Guard(errors.New(errors.KindUsage, "unknown command '%s'", args[1]))
+		}
+		PrintCommandHelp(command)
+		os.Exit(0)
+	}
+
+	command, ok := Lookup(args[0])
+	if !ok {
+		PrintHelp()
+		Guard(errors.New(errors.KindUsage, "unknown command '%s'", args[0]))
+	}
+
+	flags := command.Flags
+	if flags == nil {
+		flags = flag.NewFlagSet(command.Name, flag.ContinueOnError)
+		command.Flags = flags
+	}
+	flags.SetOutput(io.Discard)
+	registerGlobalFlags(flags)
+	if err := flags.Parse(args[1:]); err != nil {
+		if err == flag.ErrHelp {
+			PrintCommandHelp(command)
+			os.Exit(0)
+		}
+		Guard(errors.New(errors.KindUsage, "%s (see '%s help %s')", err.Error(), os.Args[0], command.Name))
+	}
+
+	journalCommand = command.Name
+	config, err := config.Load()
+	var client *notionapi.Client
+	if err == nil {
+		client = notionapi.NewClient(config.Token)
+	} else if command.ConfigOptional {
+		config = nil
+	} else {
+		Guard(err)
+	}
+
+	Guard(command.Run(config, client, flags.Args()))
+	os.Exit(0)
+}
+
+func PrintHelp() {
+	fmt.Println("Usage:", os.Args[0], "[global flags] <command> [flags] [args]")
+	fmt.Println("")
+	fmt.Println("Commands:")
+
+	width := len("help")
+	for _, command := range registry {
+		if len(command.Name) > width {
+			width = len(command.Name)
+		}
+	}
+	for _, command := range registry {
+		fmt.Printf("  %-*s  %s\n", width, command.Name, command.Summary)
+	}
+	fmt.Printf("  %-*s  %s\n", width, "help", "Show help for notion-cli or one of its commands.")
+
+	fmt.Println("")
+	fmt.Println("Global flags:")
+	printDefaults(func(flags *flag.FlagSet) { registerGlobalFlags(flags) })
+	fmt.Println("")
+	fmt.Printf("Run '%s help <command>' for more information about a command.\n", os.Args[0])
+}
+
+func PrintCommandHelp(command *Command) {
+	fmt.Println(strings.TrimSpace(fmt.Sprintf("Usage: %s %s %s", os.Args[0], command.Name, command.Usage)))
+	fmt.Println("")
+	fmt.Println(command.Summary)
+
+	if command.Flags != nil {
+		hasFlags := false
+		command.Flags.VisitAll(func(f *flag.Flag) {
+			if f.Name != "dry-run" && f.Name != "output" {
+				hasFlags = true
+			}
+		})
+		if hasFlags {
+			fmt.Println("")
+			fmt.Println("Flags:")
+			printDefaults(func(flags *flag.FlagSet) {
+				command.Flags.VisitAll(func(f *flag.Flag) {
+					if f.Name != "dry-run" && f.Name != "output" {
+						flags.Var(f.Value, f.Name, f.Usage)
+					}
+				})
+			})
+		}
+	}
+
+	if len(command.Examples) > 0 {
+		fmt.Println("")
+		fmt.Println("Examples:")
+		for _, example := range command.Examples {
+			fmt.Printf("  %s %s\n", os.Args[0], example)
+		}
+	}
+
+	fmt.Println("")
+	fmt.Println("Global flags:")
+	printDefaults(func(flags *flag.FlagSet) { registerGlobalFlags(flags) })
+}
+
+// printDefaults prints flag defaults to stdout
+// for a throwaway FlagSet populated by define.
+func printDefaults(define func(flags *flag.FlagSet)) {
+	flags := flag.NewFlagSet("", flag.ContinueOnError)
+	define(flags)
+	var builder strings.Builder
+	flags.SetOutput(&builder)
+	flags.PrintDefaults()
+	fmt.Print(builder.String())
+}
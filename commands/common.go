@@ -1,30 +1,63 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+
+	"github.com/crockeo/notion-cli/errors"
 )
 
+// Output is set by the global --output flag.
+// when it's "json", errors are reported as a JSON envelope.
+var Output = "text"
+
+type errorEnvelope struct {
+	Error errorInfo `json:"error"`
+}
+
+type errorInfo struct {
+	Kind     errors.Kind `json:"kind"`
+	Message  string      `json:"message"`
+	Cause    string      `json:"cause,omitempty"`
+	ExitCode int         `json:"exit_code"`
+}
+
+// Guard reports err on stderr and exits with the code for its Kind.
 func Guard(err error) {
-	if err != nil {
-		fmt.Println(err.Error())
-		os.Exit(1)
+	if err == nil {
+		return
+	}
+
+	kind := errors.KindOf(err)
+	if Output == "json" {
+		fmt.Fprintln(os.Stderr, string(MarshalError(err)))
+	} else if kind == errors.KindUserAbort {
+		fmt.Fprintln(os.Stderr, "aborted")
+	} else {
+		fmt.Fprintln(os.Stderr, err.Error())
 	}
+	os.Exit(kind.ExitCode())
 }
 
-func GuardOk(ok bool, message string) {
-	if !ok {
-		fmt.Println(message)
-		os.Exit(1)
+// MarshalError renders err as the JSON envelope
+// that --output json reports errors with.
+func MarshalError(err error) []byte {
+	kind := errors.KindOf(err)
+	info := errorInfo{
+		Kind:     kind,
+		Message:  err.Error(),
+		ExitCode: kind.ExitCode(),
+	}
+	if cause := errors.Cause(err); cause != err {
+		info.Cause = cause.Error()
 	}
+	bytes, _ := json.Marshal(errorEnvelope{Error: info})
+	return bytes
 }
 
-func PrintHelp() {
-	fmt.Println("Usage:", os.Args[0], "<command>")
-	fmt.Println("  capture [JSON blob]   Interactively capture a task from the terminal.")
-	fmt.Println("                        If JSON is provided, use it as additional default values.")
-	fmt.Println("")
-	fmt.Println("  complete              Tag items with the time at which they were completed.")
-	fmt.Println("")
-	fmt.Println("  dump                  Dumps information about the database in JSON.")
+func GuardOk(ok bool, message string) {
+	if !ok {
+		Guard(errors.New(errors.KindUnknown, "%s", message))
+	}
 }
@@ -2,7 +2,7 @@ package complete
 
 import (
 	"context"
-	"fmt"
+	"flag"
 	"time"
 
 	"github.com/jomei/notionapi"
@@ -10,24 +10,43 @@ import (
 	"github.com/crockeo/notion-cli/commands"
 	"github.com/crockeo/notion-cli/config"
 	"github.com/crockeo/notion-cli/database"
+	"github.com/crockeo/notion-cli/errors"
 	"github.com/crockeo/notion-cli/parse"
 )
 
-func Complete(config *config.Config, client *notionapi.Client, args []string) {
+var Command = &commands.Command{
+	Name:     "complete",
+	Summary:  "Tag items with the time at which they were completed.",
+	Usage:    "",
+	Examples: []string{"complete", "--dry-run complete"},
+	Flags:    flag.NewFlagSet("complete", flag.ContinueOnError),
+	Run:      Complete,
+}
+
+func Complete(config *config.Config, client *notionapi.Client, args []string) error {
 	database, err := database.GetSync(config, client)
-	commands.Guard(err)
+	if err != nil {
+		return err
+	}
 
 	_, ok := database.Properties[config.Complete.CompletedProperty]
-	commands.GuardOk(ok, fmt.Sprintf("config.Complete.CompletedProperty does not exist: %v", config.Complete.CompletedProperty))
+	if !ok {
+		return errors.New(errors.KindConfig, "config.Complete.CompletedProperty does not exist: %v", config.Complete.CompletedProperty)
+	}
 
 	propConfig, ok := database.Properties[config.Complete.StatusProperty]
-	commands.GuardOk(ok, fmt.Sprintf("config.Complete.StatusProperty does not exist: %v", config.Complete.StatusProperty))
+	if !ok {
+		return errors.New(errors.KindConfig, "config.Complete.StatusProperty does not exist: %v", config.Complete.StatusProperty)
+	}
 
-	_, ok = propConfig.(*notionapi.CheckboxPropertyConfig)
-	commands.GuardOk(ok, "config.Complete.StatusProperty is not a checkbox")
+	if _, ok := propConfig.(*notionapi.CheckboxPropertyConfig); !ok {
+		return errors.New(errors.KindConfig, "config.Complete.StatusProperty is not a checkbox")
+	}
 
 	checkboxProp, err := parse.ParseCheckbox(config.Complete.DoneStatus)
-	commands.Guard(err)
+	if err != nil {
+		return errors.Wrap(errors.KindConfig, err, "config.Complete.DoneStatus is not a checkbox value")
+	}
 
 	now := time.Now()
 	now = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
@@ -62,18 +81,20 @@ func Complete(config *config.Config, client *notionapi.Client, args []string) {
 				},
 			},
 		)
-		commands.Guard(err)
+		if err != nil {
+			return err
+		}
 
 		cursor = resp.NextCursor
 		hasMore = resp.HasMore
 
-		for _, result := range resp.Results {
-			_, err := client.Page.Update(
-				context.Background(),
-				notionapi.PageID(result.ID),
+		for i := range resp.Results {
+			_, err := commands.UpdatePage(
+				client,
+				&resp.Results[i],
 				&notionapi.PageUpdateRequest{
 					Properties: notionapi.Properties{
-						"Completed": &parse.DateProperty{
+						config.Complete.CompletedProperty: &parse.DateProperty{
 							Date: parse.DateObject{
 								Start: (*parse.TimelessDate)(&now),
 							},
@@ -81,7 +102,10 @@ func Complete(config *config.Config, client *notionapi.Client, args []string) {
 					},
 				},
 			)
-			commands.Guard(err)
+			if err != nil {
+				return err
+			}
 		}
 	}
+	return nil
 }
@@ -0,0 +1,294 @@
+package completion
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jomei/notionapi"
+
+	"github.com/crockeo/notion-cli/commands"
+	"github.com/crockeo/notion-cli/config"
+	"github.com/crockeo/notion-cli/database"
+	"github.com/crockeo/notion-cli/errors"
+)
+
+var (
+	flags    = flag.NewFlagSet("completion", flag.ContinueOnError)
+	complete = flags.Bool("complete", false, "Print completions for the command line after --. Used by the generated scripts.")
+)
+
+var Command = &commands.Command{
+	Name:    "completion",
+	Summary: "Generate shell completion for bash, zsh or fish.",
+	Usage:   "bash|zsh|fish",
+	Examples: []string{
+		"completion bash > /etc/bash_completion.d/notion-cli",
+		"completion zsh > \"${fpath[1]}/_notion-cli\"",
+		"completion fish > ~/.config/fish/completions/notion-cli.fish",
+	},
+	Flags:          flags,
+	ConfigOptional: true,
+	Run:            Completion,
+}
+
+func Completion(config *config.Config, client *notionapi.Client, args []string) error {
+	if *complete {
+		for _, candidate := range getCandidates(config, client, strings.Join(args, " ")) {
+			fmt.Println(candidate)
+		}
+		return nil
+	}
+
+	if len(args) != 1 {
+		return errors.New(errors.KindUsage, "expected exactly one shell: bash, zsh or fish")
+	}
+
+	var script string
+	switch args[0] {
+	case "bash":
+		script = bashScript
+	case "zsh":
+		script = zshScript
+	case "fish":
+		script = fishScript
+	default:
+		return errors.New(errors.KindUsage, "unsupported shell '%s', expected bash, zsh or fish", args[0])
+	}
+
+	program := filepath.Base(os.Args[0])
+	function := strings.Replace(program, "-", "_", -1)
+	replacer := strings.NewReplacer("{{program}}", program, "{{function}}", function)
+	fmt.Print(replacer.Replace(script))
+	return nil
+}
+
+// getCandidates suggests completions for the last word of line,
+// which is everything the user has typed up to the cursor.
+func getCandidates(config *config.Config, client *notionapi.Client, line string) []string {
+	words := strings.Fields(line)
+	if len(words) == 0 || strings.HasSuffix(line, " ") {
+		words = append(words, "")
+	}
+	if len(words) > 1 {
+		// the first word is the program itself
+		words = words[1:]
+	}
+	current := words[len(words)-1]
+	previous := words[:len(words)-1]
+
+	globalFlags := flag.NewFlagSet("", flag.ContinueOnError)
+	flags.VisitAll(func(f *flag.Flag) {
+		if f.Name == "dry-run" || f.Name == "output" {
+			globalFlags.Var(f.Value, f.Name, f.Usage)
+		}
+	})
+
+	i := 0
+	for i < len(previous) && strings.HasPrefix(previous[i], "-") {
+		if takesValue(globalFlags, previous[i]) {
+			i++
+		}
+		i++
+	}
+
+	if i >= len(previous) {
+		if len(previous) > 0 && takesValue(globalFlags, previous[len(previous)-1]) {
+			return filter(flagValues(config, client, strings.TrimLeft(previous[len(previous)-1], "-")), current)
+		}
+		if strings.HasPrefix(current, "-") {
+			return filter(flagNames(globalFlags), current)
+		}
+		return filter(commandNames(), current)
+	}
+
+	if previous[i] == "help" {
+		if i == len(previous)-1 {
+			return filter(commandNames(), current)
+		}
+		return []string{}
+	}
+
+	command, ok := commands.Lookup(previous[i])
+	if !ok {
+		return []string{}
+	}
+
+	commandFlags := flag.NewFlagSet("", flag.ContinueOnError)
+	if command.Flags != nil {
+		command.Flags.VisitAll(func(f *flag.Flag) {
+			commandFlags.Var(f.Value, f.Name, f.Usage)
+		})
+	}
+	globalFlags.VisitAll(func(f *flag.Flag) {
+		if commandFlags.Lookup(f.Name) == nil {
+			commandFlags.Var(f.Value, f.Name, f.Usage)
+		}
+	})
+
+	rest := previous[i+1:]
+	if len(rest) > 0 && takesValue(commandFlags, rest[len(rest)-1]) {
+		return filter(flagValues(config, client, strings.TrimLeft(rest[len(rest)-1], "-")), current)
+	}
+	if strings.HasPrefix(current, "-") {
+		return filter(flagNames(commandFlags), current)
+	}
+
+	if command.Complete == nil {
+		return []string{}
+	}
+	database, ok := getDatabase(config, client)
+	if !ok {
+		return []string{}
+	}
+	return filter(command.Complete(config, database, current), current)
+}
+
+func getDatabase(config *config.Config, client *notionapi.Client) (*notionapi.Database, bool) {
+	if config == nil || client == nil {
+		return nil, false
+	}
+	database, err := database.GetCached(config, client, -1)
+	return database, err == nil
+}
+
+// takesValue reports whether word is a flag which consumes the next word,
+// i.e. a non-boolean flag written without "=value".
+func takesValue(flags *flag.FlagSet, word string) bool {
+	if !strings.HasPrefix(word, "-") || strings.Contains(word, "=") {
+		return false
+	}
+	f := flags.Lookup(strings.TrimLeft(word, "-"))
+	if f == nil {
+		return false
+	}
+	if boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && boolFlag.IsBoolFlag() {
+		return false
+	}
+	return true
+}
+
+// flagValues suggests values for flags which take one of a few values.
+func flagValues(config *config.Config, client *notionapi.Client, flagName string) []string {
+	switch flagName {
+	case "propinfo":
+		database, ok := getDatabase(config, client)
+		if !ok {
+			return []string{}
+		}
+		candidates := []string{}
+		for _, propName := range commands.CompleteProperties(config, database, "") {
+			propName = strings.TrimSuffix(propName, "=")
+			candidates = append(candidates, fmt.Sprintf(`{"properties":{"%s":"`, propName))
+		}
+		return candidates
+	case "output":
+		return []string{"text", "json"}
+	case "on-duplicate":
+		return []string{"skip", "create", "fail", "open", "merge"}
+	case "format":
+		return []string{"jsonl", "csv", "yaml"}
+	case "type":
+		return []string{"page", "database"}
+	case "as":
+		return []string{"auto", "todo", "event"}
+	case "then":
+		return []string{"open", "append"}
+	case "template":
+		if config == nil {
+			return []string{}
+		}
+		names := []string{}
+		for name := range config.Capture.Templates {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names
+	}
+	return []string{}
+}
+
+func flagNames(flags *flag.FlagSet) []string {
+	names := []string{}
+	flags.VisitAll(func(f *flag.Flag) {
+		names = append(names, "-"+f.Name)
+	})
+	return names
+}
+
+func commandNames() []string {
+	names := []string{}
+	for _, command := range commands.Registered() {
+		names = append(names, command.Name)
+	}
+	return append(names, "help")
+}
+
+func filter(candidates []string, current string) []string {
+	// flags work with one or two dashes,
+	// so we match whichever the user started typing
+	dashes := ""
+	if strings.HasPrefix(current, "--") {
+		dashes = "-"
+	}
+
+	// the same goes for a quote at the start of the word,
+	// e.g. -propinfo '{"properties": ...
+	quote := ""
+	if strings.HasPrefix(current, "'") || strings.HasPrefix(current, "\"") {
+		quote = current[:1]
+	}
+
+	filtered := []string{}
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, "-") {
+			candidate = dashes + candidate
+		}
+		candidate = quote + candidate
+		if strings.HasPrefix(candidate, current) {
+			filtered = append(filtered, candidate)
+		}
+	}
+	return filtered
+}
+
+const bashScript = `# bash completion for {{program}}
+_{{function}}() {
+	local line="${COMP_LINE:0:COMP_POINT}"
+	local word="${line##* }"
+	# bash splits words on '=' and ':',
+	# so we only replace what comes after the last one
+	local strip="${word%"${word##*[=:]}"}"
+
+	local IFS=$'\n'
+	local candidates=($({{program}} completion -complete -- "$line" 2>/dev/null))
+	COMPREPLY=("${candidates[@]#"$strip"}")
+	if [[ ${#COMPREPLY[@]} -eq 1 && "${COMPREPLY[0]}" == *= ]]; then
+		compopt -o nospace
+	fi
+}
+complete -o default -F _{{function}} {{program}}
+`
+
+const zshScript = `#compdef {{program}}
+# zsh completion for {{program}}
+_{{function}}() {
+	local -a candidates assignments
+	candidates=("${(@f)$({{program}} completion -complete -- "${(j: :)words[1,CURRENT]}" 2>/dev/null)}")
+	assignments=(${(M)candidates:#*=})
+	candidates=(${candidates:#*=})
+	compadd -U -Q -S '' -a assignments
+	compadd -U -Q -a candidates
+}
+compdef _{{function}} {{program}}
+`
+
+const fishScript = `# fish completion for {{program}}
+function __{{function}}_complete
+	{{program}} completion -complete -- (commandline -cp) 2>/dev/null
+end
+complete -c {{program}} -f -a '(__{{function}}_complete)'
+`
@@ -0,0 +1,143 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/jomei/notionapi"
+
+	"github.com/crockeo/notion-cli/errors"
+)
+
+// DryRun is set by the global --dry-run flag.
+// commands should send every mutation through the helpers below
+// so that a dry run goes through all of the parsing and prompting
+// and then prints what it would have sent instead.
+var DryRun bool
+
+var dryRunLock sync.Mutex
+
+type dryRunEntry struct {
+	Action  string      `json:"action"`
+	PageID  string      `json:"page_id,omitempty"`
+	Request interface{} `json:"request"`
+}
+
+func printDryRun(entry dryRunEntry) error {
+	bytes, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// batch commands mutate from several goroutines,
+	// so we make sure that entries aren't interleaved
+	dryRunLock.Lock()
+	defer dryRunLock.Unlock()
+	fmt.Println(string(bytes))
+	return nil
+}
+
+// CreatePage creates a page, or prints the request during a dry run.
+// the API only takes MaxChildren blocks with the page,
+// so the rest of a long body is appended afterwards.
+// the returned page is nil during a dry run.
+func CreatePage(client *notionapi.Client, request *notionapi.PageCreateRequest) (*notionapi.Page, error) {
+	rest := []notionapi.Block{}
+	if len(request.Children) > MaxChildren {
+		first := *request
+		first.Children, rest = request.Children[:MaxChildren], request.Children[MaxChildren:]
+		request = &first
+	}
+
+	if DryRun {
+		if err := printDryRun(dryRunEntry{Action: "create", Request: request}); err != nil {
+			return nil, err
+		}
+		return nil, AppendBlocks(client, "", rest)
+	}
+	page, err := client.Page.Create(context.Background(), request)
+	if err != nil {
+		return nil, err
+	}
+	if err := journalCreate(page); err != nil {
+		warnJournal(err)
+	}
+	if err := AppendBlocks(client, notionapi.BlockID(page.ID), rest); err != nil {
+		return page, errors.Wrap(errors.KindOf(err), err, "created the page, but failed to append the end of its body")
+	}
+	return page, nil
+}
+
+// UpdatePage updates a page, or prints the request during a dry run.
+// page is the page as it was before the update,
+// which is what goes in the undo journal.
+// the returned page is nil during a dry run.
+func UpdatePage(client *notionapi.Client, page *notionapi.Page, request *notionapi.PageUpdateRequest) (*notionapi.Page, error) {
+	if DryRun {
+		return nil, printDryRun(dryRunEntry{Action: "update", PageID: string(page.ID), Request: request})
+	}
+	updated, err := client.Page.Update(context.Background(), notionapi.PageID(page.ID), request)
+	if err != nil {
+		return nil, err
+	}
+	if err := journalUpdate(page, request); err != nil {
+		warnJournal(err)
+	}
+	return updated, nil
+}
+
+// warnJournal reports that we couldn't journal a change.
+// the change has already been made by then,
+// so failing the command would only make things more confusing.
+func warnJournal(err error) {
+	fmt.Fprintf(os.Stderr, "failed to write the undo journal: %s\n", err.Error())
+}
+
+// MaxChildren is how many blocks the API accepts in one request.
+const MaxChildren = 100
+
+// AppendBlocks appends children to a block (or page),
+// or prints the requests during a dry run.
+// children are sent in batches of MaxChildren,
+// so a failure part way through can leave some of them appended.
+func AppendBlocks(client *notionapi.Client, blockID notionapi.BlockID, children []notionapi.Block) error {
+	for start := 0; start < len(children); start += MaxChildren {
+		end := start + MaxChildren
+		if end > len(children) {
+			end = len(children)
+		}
+		request := &notionapi.AppendBlockChildrenRequest{Children: children[start:end]}
+		if DryRun {
+			if err := printDryRun(dryRunEntry{Action: "append", PageID: string(blockID), Request: request}); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := client.Block.AppendChildren(context.Background(), blockID, request); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteBlock deletes a block, or prints the request during a dry run.
+func DeleteBlock(client *notionapi.Client, blockID notionapi.BlockID) error {
+	if DryRun {
+		return printDryRun(dryRunEntry{Action: "delete", PageID: string(blockID)})
+	}
+	_, err := client.Block.Delete(context.Background(), blockID)
+	return err
+}
+
+// UpdateDatabase updates a database's schema,
+// or prints the request during a dry run.
+// the returned database is nil during a dry run.
+func UpdateDatabase(client *notionapi.Client, databaseID notionapi.DatabaseID, request *notionapi.DatabaseUpdateRequest) (*notionapi.Database, error) {
+	if DryRun {
+		return nil, printDryRun(dryRunEntry{Action: "update_database", PageID: string(databaseID), Request: request})
+	}
+	return client.Database.Update(context.Background(), databaseID, request)
+}
@@ -2,6 +2,7 @@ package dump
 
 import (
 	"encoding/json"
+	"flag"
 	"os"
 
 	"github.com/jomei/notionapi"
@@ -9,24 +10,69 @@ import (
 	"github.com/crockeo/notion-cli/commands"
 	"github.com/crockeo/notion-cli/config"
 	"github.com/crockeo/notion-cli/database"
+	"github.com/crockeo/notion-cli/errors"
 )
 
-func Dump(config *config.Config, client *notionapi.Client, complete []string) {
-	database, err := database.GetSync(config, client)
-	commands.Guard(err)
+var (
+	flags  = flag.NewFlagSet("dump", flag.ContinueOnError)
+	format = flags.String("format", "json", "What to dump: json for notion-cli's own description of the database, or jsonschema for a JSON Schema of -propinfo.")
+)
+
+var Command = &commands.Command{
+	Name:     "dump",
+	Summary:  "Dumps information about the database in JSON.",
+	Usage:    "[-format json|jsonschema]",
+	Examples: []string{"dump", "dump -format jsonschema > propinfo.schema.json"},
+	Flags:    flags,
+	Run:      Dump,
+}
+
+func Dump(config *config.Config, client *notionapi.Client, args []string) error {
+	if *format != "json" && *format != "jsonschema" {
+		return errors.New(errors.KindUsage, "-format must be json or jsonschema, got '%s'", *format)
+	}
 
+	// dump always fetches a fresh schema,
+	// and refreshes the cache used by shell completion on the way
+	database, err := database.GetCached(config, client, 0)
+	if err != nil {
+		return err
+	}
+
+	if *format == "jsonschema" {
+		bytes, err := json.MarshalIndent(newJSONSchema(config, database), "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(append(bytes, '\n'))
+		return err
+	}
+
+	bytes, err := json.Marshal(NewInfo(config, database))
+	if err != nil {
+		return err
+	}
+
+	_, err = os.Stdout.Write(bytes)
+	return err
+}
+
+// NewInfo describes db the way dump prints it.
+// dumps double as snapshots of the schema for schema diff,
+// which is why properties carry their IDs.
+func NewInfo(config *config.Config, db *notionapi.Database) NotionCliInfo {
 	info := NotionCliInfo{
 		Order:      config.Capture.Order,
 		Properties: map[string]PropInfo{},
 	}
-	for propName, propConfig := range database.Properties {
+	for propName, propConfig := range db.Properties {
 		if _, ok := propConfig.(*notionapi.FormulaPropertyConfig); ok {
 			// we don't prompt for Formulas during capture
 			// so we don't want to tell people that they exist
 			continue
 		}
 
-		propInfo := PropInfo{Type: string(propConfig.GetType())}
+		propInfo := PropInfo{ID: database.PropertyID(propConfig), Type: string(propConfig.GetType())}
 		if defaultValue, ok := config.Capture.Defaults[propName]; ok {
 			propInfo.Default = &defaultValue
 		}
@@ -48,11 +94,7 @@ func Dump(config *config.Config, client *notionapi.Client, complete []string) {
 		info.Properties[propName] = propInfo
 	}
 
-	bytes, err := json.Marshal(info)
-	commands.Guard(err)
-
-	_, err = os.Stdout.Write(bytes)
-	commands.Guard(err)
+	return info
 }
 
 type NotionCliInfo struct {
@@ -61,6 +103,7 @@ type NotionCliInfo struct {
 }
 
 type PropInfo struct {
+	ID      string   `json:"id,omitempty"`
 	Type    string   `json:"type"`
 	Default *string  `json:"default,omitempty"`
 	Options []string `json:"options,omitempty"`
@@ -0,0 +1,137 @@
+package dump
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jomei/notionapi"
+
+	"github.com/crockeo/notion-cli/config"
+	"github.com/crockeo/notion-cli/database"
+)
+
+// jsonSchema is the subset of JSON Schema (draft 2020-12)
+// that we need to describe a -propinfo payload.
+type jsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	MinLength            int                    `json:"minLength,omitempty"`
+	Default              *string                `json:"default,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+	AnyOf                []*jsonSchema          `json:"anyOf,omitempty"`
+}
+
+// JSON Schema patterns don't have flags, so checkboxPattern spells out
+// both cases of the values ParseCheckbox accepts.
+const (
+	checkboxPattern = `^([Tt][Rr][Uu][Ee]|[Ff][Aa][Ll][Ss][Ee]|[Yy]([Ee][Ss])?|[Nn][Oo]?)$`
+	numberPattern   = `^[-+]?(\d+(\.\d*)?|\.\d+)([eE][-+]?\d+)?$`
+	relationPattern = `^\s*$|^\s*[0-9a-fA-F-]{32,36}(\s*,\s*[0-9a-fA-F-]{32,36})*\s*$`
+)
+
+// newJSONSchema describes a -propinfo payload for db,
+// with every property's value as the string that parse.Property reads.
+// the title can be given either as "title" or as the title property,
+// so the schema requires one or the other.
+func newJSONSchema(config *config.Config, db *notionapi.Database) *jsonSchema {
+	no := false
+	properties := map[string]*jsonSchema{}
+	for propName, propConfig := range db.Properties {
+		property, ok := propertySchema(propConfig)
+		if !ok {
+			continue
+		}
+		if defaultValue, ok := config.Capture.Defaults[propName]; ok {
+			property.Default = &defaultValue
+		}
+		properties[propName] = property
+	}
+
+	anyOf := []*jsonSchema{{Required: []string{"title"}}}
+	if titleProp, ok := database.TitleProperty(db); ok {
+		anyOf = append(anyOf, &jsonSchema{
+			Required: []string{"properties"},
+			Properties: map[string]*jsonSchema{
+				"properties": {Required: []string{titleProp}},
+			},
+		})
+	}
+
+	return &jsonSchema{
+		Schema:      "https://json-schema.org/draft/2020-12/schema",
+		Title:       fmt.Sprintf("notion-cli capture -propinfo for %s", database.DatabaseTitle(db)),
+		Type:        "object",
+		Description: "A page to capture. Every property value is a string, in the same format as when capturing interactively.",
+		Properties: map[string]*jsonSchema{
+			"title": {Type: "string", MinLength: 1, Description: "The page's title."},
+			"body":  {Type: "string", Description: "The page's body, in Markdown."},
+			"properties": {
+				Type:                 "object",
+				Properties:           properties,
+				AdditionalProperties: &no,
+			},
+		},
+		AdditionalProperties: &no,
+		AnyOf:                anyOf,
+	}
+}
+
+// propertySchema describes the values parse.Property accepts for propConfig.
+// ok is false for the properties capture can't set, like formulas.
+func propertySchema(propConfig notionapi.PropertyConfig) (*jsonSchema, bool) {
+	property := &jsonSchema{Type: "string"}
+	switch propConfig := propConfig.(type) {
+	case *notionapi.TitlePropertyConfig:
+		property.MinLength = 1
+	case *notionapi.RichTextPropertyConfig, *notionapi.PhoneNumberPropertyConfig:
+	case *notionapi.NumberPropertyConfig:
+		property.Pattern = numberPattern
+	case *notionapi.SelectPropertyConfig:
+		property.Enum = optionNames(propConfig.Select.Options)
+	case *notionapi.MultiSelectPropertyConfig:
+		property.Pattern = multiSelectPattern(propConfig.MultiSelect.Options)
+		property.Description = "Comma separated options."
+	case *notionapi.DatePropertyConfig:
+		// no format, since neither date nor date-time allows 'tomorrow'
+		property.Description = "A date like 2026-10-19, a time like 2026-10-19T09:30:00Z, or something like 'tomorrow'."
+	case *notionapi.CheckboxPropertyConfig:
+		property.Pattern = checkboxPattern
+	case *notionapi.URLPropertyConfig:
+		property.Format = "uri"
+	case *notionapi.EmailPropertyConfig:
+		property.Format = "email"
+	case *notionapi.RelationPropertyConfig:
+		property.Pattern = relationPattern
+		property.Description = "Comma separated page IDs."
+	default:
+		return nil, false
+	}
+	return property, true
+}
+
+func optionNames(options []notionapi.Option) []string {
+	names := make([]string, len(options))
+	for i, option := range options {
+		names[i] = option.Name
+	}
+	return names
+}
+
+// multiSelectPattern matches a comma separated list of options,
+// with the same whitespace that ParseMultiSelect trims.
+func multiSelectPattern(options []notionapi.Option) string {
+	quoted := make([]string, len(options))
+	for i, option := range options {
+		quoted[i] = regexp.QuoteMeta(option.Name)
+	}
+	option := `\s*(` + strings.Join(quoted, "|") + `)?\s*`
+	return "^" + option + "(," + option + ")*$"
+}
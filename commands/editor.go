@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+)
+
+// HasEditor reports whether EditBody opens $EDITOR,
+// rather than reading from stdin.
+func HasEditor() bool {
+	_, ok := os.LookupEnv("EDITOR")
+	return ok
+}
+
+// EditBody lets someone write a Markdown body in $EDITOR,
+// starting from initial.
+// without an $EDITOR, the body is read from stdin instead.
+func EditBody(initial []byte) ([]byte, error) {
+	contents := initial
+
+	editor, ok := os.LookupEnv("EDITOR")
+	if !ok {
+		fmt.Println("Body:")
+		fmt.Print(string(initial))
+		body := make([]byte, 512)
+		for {
+			n, err := os.Stdin.Read(body)
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+			contents = append(contents, body[:n]...)
+			if err == io.EOF {
+				break
+			}
+		}
+	} else {
+		file, err := ioutil.TempFile("", "*.md")
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(file.Name())
+
+		if _, err := file.Write(initial); err != nil {
+			return nil, err
+		}
+		if err := file.Close(); err != nil {
+			return nil, err
+		}
+
+		cmd := exec.Command(editor, file.Name())
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		if err := cmd.Run(); err != nil {
+			return nil, err
+		}
+
+		contents, err = os.ReadFile(file.Name())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return contents, nil
+}
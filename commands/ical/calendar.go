@@ -0,0 +1,63 @@
+package ical
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// calendar builds an iCalendar (RFC 5545) document line by line.
+type calendar struct {
+	builder strings.Builder
+}
+
+func (c *calendar) line(name string, value string) {
+	c.fold(name + ":" + value)
+}
+
+func (c *calendar) text(name string, value string) {
+	c.line(name, escapeText(value))
+}
+
+// date writes an all-day date, or a time in UTC.
+func (c *calendar) date(name string, date time.Time, timeless bool) {
+	if timeless {
+		c.line(name+";VALUE=DATE", date.Format("20060102"))
+	} else {
+		c.line(name, date.UTC().Format("20060102T150405Z"))
+	}
+}
+
+// fold splits lines longer than 75 octets,
+// without splitting a character in half.
+// continuation lines start with a space,
+// so they only have room for 74 octets of the line.
+func (c *calendar) fold(line string) {
+	width := 75
+	for len(line) > width {
+		cut := width
+		for !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		c.builder.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		width = 74
+	}
+	c.builder.WriteString(line + "\r\n")
+}
+
+func (c *calendar) String() string {
+	return c.builder.String()
+}
+
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+)
+
+func escapeText(value string) string {
+	return textEscaper.Replace(value)
+}
@@ -0,0 +1,195 @@
+package ical
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jomei/notionapi"
+
+	"github.com/crockeo/notion-cli/commands"
+	"github.com/crockeo/notion-cli/config"
+	"github.com/crockeo/notion-cli/database"
+	"github.com/crockeo/notion-cli/errors"
+	"github.com/crockeo/notion-cli/parse"
+)
+
+var (
+	flags = flag.NewFlagSet("ical", flag.ContinueOnError)
+	file  = flags.String("file", "", "File to write the calendar to, instead of stdout.")
+	as    = flags.String("as", "auto", "How items are exported: todo, event, or auto for events when they have an end date and to-dos otherwise.")
+	open  = flags.Bool("open", false, "Only export items which aren't complete.")
+)
+
+var Command = &commands.Command{
+	Name:    "ical",
+	Summary: "Export every item with a date as an iCalendar file.",
+	Usage:   "[flags]",
+	Examples: []string{
+		"ical > tasks.ics",
+		"ical -file ~/Calendars/notion.ics",
+		"ical -as event -open -file ~/Calendars/notion.ics",
+	},
+	Flags: flags,
+	Run:   ICal,
+}
+
+func ICal(config *config.Config, client *notionapi.Client, args []string) error {
+	dateProperty := config.Properties.Date
+	if dateProperty == "" {
+		return errors.New(errors.KindConfig, "config.Properties.Date must be set to use ical")
+	}
+	if *as != "auto" && *as != "todo" && *as != "event" {
+		return errors.New(errors.KindUsage, "-as must be todo, event, or auto, got '%s'", *as)
+	}
+
+	filters := []notionapi.PropertyFilter{
+		{
+			Property: dateProperty,
+			Date:     &notionapi.DateFilterCondition{IsNotEmpty: true},
+		},
+	}
+	if *open {
+		if openFilter := database.OpenFilter(config); openFilter != nil {
+			filters = append(filters, *openFilter)
+		}
+	}
+	pages, err := database.QueryAll(config, client, &notionapi.DatabaseQueryRequest{
+		CompoundFilter: &notionapi.CompoundFilter{notionapi.FilterOperatorAND: filters},
+	})
+	if err != nil {
+		return err
+	}
+
+	// the order of pages from the API isn't stable,
+	// and a file which changes on every export makes for noisy syncing
+	sort.Slice(pages, func(i, j int) bool {
+		return pages[i].ID < pages[j].ID
+	})
+
+	contents := render(config, pages)
+	if *file == "" {
+		fmt.Print(contents)
+		return nil
+	}
+	return writeFile(*file, []byte(contents))
+}
+
+func render(config *config.Config, pages []notionapi.Page) string {
+	cal := &calendar{}
+	cal.line("BEGIN", "VCALENDAR")
+	cal.line("VERSION", "2.0")
+	cal.line("PRODID", "-//crockeo//notion-cli//EN")
+	cal.line("CALSCALE", "GREGORIAN")
+	for i := range pages {
+		renderPage(cal, config, &pages[i])
+	}
+	cal.line("END", "VCALENDAR")
+	return cal.String()
+}
+
+func renderPage(cal *calendar, config *config.Config, page *notionapi.Page) {
+	date, ok := page.Properties[config.Properties.Date].(*notionapi.DateProperty)
+	if !ok || date.Date.Start == nil {
+		return
+	}
+	start, timeless := parse.FromNotionDate(date.Date.Start)
+	var end *time.Time
+	if date.Date.End != nil {
+		endDate, _ := parse.FromNotionDate(date.Date.End)
+		end = &endDate
+	}
+
+	component := "VTODO"
+	if *as == "event" || *as == "auto" && end != nil {
+		component = "VEVENT"
+	}
+
+	cal.line("BEGIN", component)
+	cal.line("UID", database.NormalizeID(string(page.ID))+"@notion-cli")
+	// stamping with the last edit rather than the time of the export
+	// means that unchanged items come out exactly the same
+	cal.date("DTSTAMP", page.LastEditedTime, false)
+	cal.date("LAST-MODIFIED", page.LastEditedTime, false)
+	cal.text("SUMMARY", database.PageTitle(page))
+	if page.URL != "" {
+		cal.line("URL", page.URL)
+	}
+	if tags, ok := page.Properties[config.Properties.Tags].(*notionapi.MultiSelectProperty); ok && len(tags.MultiSelect) > 0 {
+		names := make([]string, len(tags.MultiSelect))
+		for i, option := range tags.MultiSelect {
+			names[i] = escapeText(option.Name)
+		}
+		cal.line("CATEGORIES", strings.Join(names, ","))
+	}
+
+	if component == "VEVENT" {
+		cal.date("DTSTART", start, timeless)
+		// the end of an all-day event is the day after it,
+		// while Notion's date ranges include their last day
+		switch {
+		case end != nil && timeless:
+			cal.date("DTEND", end.AddDate(0, 0, 1), true)
+		case end != nil:
+			cal.date("DTEND", *end, false)
+		case timeless:
+			cal.date("DTEND", start.AddDate(0, 0, 1), true)
+		}
+	} else {
+		if end != nil {
+			cal.date("DTSTART", start, timeless)
+			cal.date("DUE", *end, timeless)
+		} else {
+			cal.date("DUE", start, timeless)
+		}
+		renderCompletion(cal, config, page)
+	}
+	cal.line("END", component)
+}
+
+// renderCompletion uses the same properties as complete
+// to tell whether a to-do is done, and when.
+func renderCompletion(cal *calendar, config *config.Config, page *notionapi.Page) {
+	status, ok := page.Properties[config.Complete.StatusProperty].(*notionapi.CheckboxProperty)
+	if !ok {
+		return
+	}
+	done, err := parse.ParseCheckbox(config.Complete.DoneStatus)
+	if err != nil || status.Checkbox != done.Checkbox {
+		cal.line("STATUS", "NEEDS-ACTION")
+		return
+	}
+
+	cal.line("STATUS", "COMPLETED")
+	if completed, ok := page.Properties[config.Complete.CompletedProperty].(*notionapi.DateProperty); ok && completed.Date.Start != nil {
+		// COMPLETED has to be a time, so a date means the start of that day
+		completedAt, _ := parse.FromNotionDate(completed.Date.Start)
+		cal.date("COMPLETED", completedAt, false)
+	}
+}
+
+// writeFile replaces path all at once,
+// so that a calendar app reading it never sees half of an export.
+func writeFile(path string, contents []byte) error {
+	temp, err := os.CreateTemp(filepath.Dir(path), ".*.ics")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(contents); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(temp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), path)
+}
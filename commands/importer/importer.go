@@ -0,0 +1,247 @@
+package importer
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jomei/notionapi"
+
+	"github.com/crockeo/notion-cli/commands"
+	"github.com/crockeo/notion-cli/commands/capture"
+	"github.com/crockeo/notion-cli/config"
+	"github.com/crockeo/notion-cli/database"
+	"github.com/crockeo/notion-cli/errors"
+)
+
+var (
+	flags       = flag.NewFlagSet("import", flag.ContinueOnError)
+	format      = flags.String("format", "", "Format of the file: todotxt, taskwarrior, or org. Guessed from the file's extension when not set.")
+	skipDone    = flags.Bool("skip-done", false, "Leave out tasks which are already done.")
+	concurrency = flags.Int("concurrency", 3, "Number of pages created at once.")
+)
+
+var Command = &commands.Command{
+	Name:    "import",
+	Summary: "Import tasks from todo.txt, Taskwarrior, or org-mode.",
+	Usage:   "[flags] <file|->",
+	Examples: []string{
+		"import todo.txt",
+		"task export | import -format taskwarrior -",
+		"import -skip-done ~/org/inbox.org",
+	},
+	Flags: flags,
+	Run:   Import,
+}
+
+// task is an item from any of the formats,
+// before it's mapped onto the database.
+// dates are already in a format that parse.Property reads.
+type task struct {
+	Line      int
+	Title     string
+	Done      bool
+	Completed string
+	Priority  string
+	Contexts  []string
+	Projects  []string
+	Tags      []string
+	Due       string
+	Fields    map[string]string
+	Body      string
+}
+
+type reader func(io.Reader) ([]task, error)
+
+var readers = map[string]reader{
+	"todotxt":     readTodoTxt,
+	"taskwarrior": readTaskwarrior,
+	"org":         readOrg,
+}
+
+var extensions = map[string]string{
+	".txt":  "todotxt",
+	".json": "taskwarrior",
+	".org":  "org",
+}
+
+func Import(config *config.Config, client *notionapi.Client, args []string) error {
+	if len(args) != 1 {
+		return errors.New(errors.KindUsage, "expected a file, or - for stdin")
+	}
+	path := args[0]
+
+	formatName := *format
+	if formatName == "" {
+		formatName = extensions[filepath.Ext(path)]
+	}
+	read, ok := readers[formatName]
+	if !ok {
+		return errors.New(errors.KindUsage, "unknown -format '%s', expected todotxt, taskwarrior, or org", formatName)
+	}
+
+	databaseChan, errChan := database.Get(config, client)
+
+	var input io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		input = file
+	}
+	tasks, err := read(input)
+	if err != nil {
+		return errors.Wrap(errors.KindValidation, err, "could not read %s as %s", path, formatName)
+	}
+
+	db, err := database.Join(databaseChan, errChan)
+	if err != nil {
+		return err
+	}
+
+	rows, unmapped, err := toRows(getMapping(config, formatName), db, tasks)
+	if err != nil {
+		return err
+	}
+	reportUnmapped(formatName, unmapped)
+	if len(rows) == 0 {
+		fmt.Println("nothing to import")
+		return nil
+	}
+	if err := resolveRelations(client, db, path, rows); err != nil {
+		return err
+	}
+	return capture.CaptureRows(config, client, db, nil, path, rows, *concurrency)
+}
+
+func getMapping(c *config.Config, formatName string) config.ImportMapping {
+	var mapping config.ImportMapping
+	switch formatName {
+	case "todotxt":
+		mapping = c.Import.TodoTxt
+	case "taskwarrior":
+		mapping = c.Import.Taskwarrior
+	case "org":
+		mapping = c.Import.Org
+	}
+
+	fallback := func(value *string, fallback string) {
+		if *value == "" {
+			*value = fallback
+		}
+	}
+	fallback(&mapping.Priority, c.Properties.Priority)
+	fallback(&mapping.Tags, c.Properties.Tags)
+	fallback(&mapping.Due, c.Properties.Date)
+	fallback(&mapping.Done, c.Complete.StatusProperty)
+	fallback(&mapping.DoneValue, c.Complete.DoneStatus)
+	fallback(&mapping.DoneValue, "true")
+	fallback(&mapping.Completed, c.Complete.CompletedProperty)
+	return mapping
+}
+
+// toRows maps tasks onto the database's properties.
+// fields without a property are counted in unmapped rather than failing,
+// since most files have something we don't have a place for.
+func toRows(mapping config.ImportMapping, db *notionapi.Database, tasks []task) ([]capture.BatchRow, map[string]int, error) {
+	titleProperty, ok := database.TitleProperty(db)
+	if !ok {
+		return nil, nil, errors.New(errors.KindConfig, "the database doesn't have a title property")
+	}
+
+	unmapped := map[string]int{}
+	rows := []capture.BatchRow{}
+	for _, task := range tasks {
+		if task.Done && *skipDone {
+			continue
+		}
+
+		properties := map[string]string{titleProperty: task.Title}
+		set := func(field string, propName string, value string) {
+			if value == "" {
+				return
+			}
+			if propName == "" {
+				unmapped[field]++
+				return
+			}
+			properties[propName] = value
+		}
+
+		priority := task.Priority
+		if translated, ok := mapping.Priorities[priority]; ok {
+			priority = translated
+		}
+		set("priority", mapping.Priority, priority)
+		set("contexts", mapping.Contexts, strings.Join(task.Contexts, ","))
+		set("projects", mapping.Projects, strings.Join(task.Projects, ","))
+		set("tags", mapping.Tags, strings.Join(task.Tags, ","))
+		set("due", mapping.Due, task.Due)
+		if task.Done {
+			set("done", mapping.Done, mapping.DoneValue)
+			set("completed", mapping.Completed, task.Completed)
+		}
+		for field, value := range task.Fields {
+			set(field, mapping.Fields[field], value)
+		}
+
+		propInfo := &capture.PropInfo{Properties: properties}
+		if task.Body != "" {
+			body := task.Body
+			propInfo.Body = &body
+		}
+		rows = append(rows, capture.BatchRow{Line: task.Line, PropInfo: propInfo})
+	}
+	return rows, unmapped, nil
+}
+
+// resolveRelations looks up the pages that relation properties name by title,
+// the same way quick capture does, since tasks only know their projects by name.
+// most tasks share a handful of projects, so each value is only looked up once.
+func resolveRelations(client *notionapi.Client, db *notionapi.Database, source string, rows []capture.BatchRow) error {
+	resolved := map[string]string{}
+	for _, row := range rows {
+		for propName, propValue := range row.PropInfo.Properties {
+			propConfig, ok := db.Properties[propName].(*notionapi.RelationPropertyConfig)
+			if !ok {
+				continue
+			}
+			key := propName + "\x00" + propValue
+			ids, ok := resolved[key]
+			if !ok {
+				var err error
+				ids, err = capture.ResolveRelation(client, propConfig, propValue)
+				if err != nil {
+					return errors.Wrap(errors.KindOf(err), err, "could not relate the task at %s:%d, nothing was imported", source, row.Line)
+				}
+				resolved[key] = ids
+			}
+			row.PropInfo.Properties[propName] = ids
+		}
+	}
+	return nil
+}
+
+func reportUnmapped(formatName string, unmapped map[string]int) {
+	if len(unmapped) == 0 {
+		return
+	}
+
+	fields := []string{}
+	for field := range unmapped {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	fmt.Fprintln(os.Stderr, "these fields have no property and won't be imported:")
+	for _, field := range fields {
+		fmt.Fprintf(os.Stderr, "  %s (%d tasks)\n", field, unmapped[field])
+	}
+	fmt.Fprintf(os.Stderr, "map them to properties under import.%s in the config\n", formatName)
+}
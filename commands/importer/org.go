@@ -0,0 +1,164 @@
+package importer
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	orgHeadline = regexp.MustCompile(`^\*+\s+(.*)$`)
+	orgPriority = regexp.MustCompile(`^\[#([A-Z0-9])\]$`)
+	orgTags     = regexp.MustCompile(`\s+(:[^\s]+:)$`)
+	orgPlanning = regexp.MustCompile(`(SCHEDULED|DEADLINE|CLOSED):\s*[<\[]([^>\]]+)[>\]]`)
+	orgProperty = regexp.MustCompile(`^:([^:\s]+):\s*(.*)$`)
+	orgKeywords = regexp.MustCompile(`^#\+(?:SEQ_|TYP_)?TODO:(.*)$`)
+)
+
+// readOrg reads every headline with a TODO keyword as a task.
+// DEADLINE is the due date, CLOSED is when it was completed,
+// and the property drawer and SCHEDULED end up in the task's fields.
+// headlines without a keyword, and everything under them, are left out.
+func readOrg(reader io.Reader) ([]task, error) {
+	todo := map[string]bool{"TODO": true, "NEXT": true, "WAITING": true}
+	done := map[string]bool{"DONE": true, "CANCELLED": true, "CANCELED": true}
+
+	tasks := []task{}
+	var current *task
+	var body []string
+	drawer := ""
+	finish := func() {
+		if current != nil {
+			current.Body = strings.TrimSpace(strings.Join(body, "\n"))
+			if current.Body != "" {
+				current.Body += "\n"
+			}
+			tasks = append(tasks, *current)
+		}
+		current = nil
+		body = nil
+	}
+
+	scanner := bufio.NewScanner(reader)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		trimmed := strings.TrimSpace(text)
+
+		if match := orgKeywords.FindStringSubmatch(trimmed); match != nil {
+			todo, done = parseOrgKeywords(match[1])
+			continue
+		}
+
+		if match := orgHeadline.FindStringSubmatch(text); match != nil {
+			finish()
+			drawer = ""
+			current = parseOrgHeadline(line, match[1], todo, done)
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		switch {
+		case drawer != "":
+			if strings.EqualFold(trimmed, ":END:") {
+				drawer = ""
+			} else if match := orgProperty.FindStringSubmatch(trimmed); match != nil && drawer == "PROPERTIES" {
+				current.Fields[strings.ToLower(match[1])] = match[2]
+			}
+		case orgPlanning.MatchString(trimmed):
+			for _, match := range orgPlanning.FindAllStringSubmatch(trimmed, -1) {
+				date := orgDate(match[2])
+				switch match[1] {
+				case "DEADLINE":
+					current.Due = date
+				case "CLOSED":
+					current.Completed = date
+				case "SCHEDULED":
+					current.Fields["scheduled"] = date
+				}
+			}
+		case strings.HasPrefix(trimmed, ":") && strings.HasSuffix(trimmed, ":") && len(trimmed) > 2 && !strings.Contains(trimmed, " "):
+			drawer = strings.ToUpper(strings.Trim(trimmed, ":"))
+		default:
+			body = append(body, trimmed)
+		}
+	}
+	finish()
+	return tasks, scanner.Err()
+}
+
+func parseOrgHeadline(line int, headline string, todo, done map[string]bool) *task {
+	words := strings.Fields(headline)
+	if len(words) == 0 || !todo[words[0]] && !done[words[0]] {
+		return nil
+	}
+
+	task := &task{Line: line, Done: done[words[0]], Fields: map[string]string{}}
+	if words[0] != "TODO" && words[0] != "DONE" {
+		task.Fields["keyword"] = words[0]
+	}
+	words = words[1:]
+	if len(words) > 0 {
+		if match := orgPriority.FindStringSubmatch(words[0]); match != nil {
+			task.Priority = match[1]
+			words = words[1:]
+		}
+	}
+
+	title := strings.Join(words, " ")
+	if match := orgTags.FindStringSubmatch(" " + title); match != nil {
+		task.Tags = strings.FieldsFunc(match[1], func(r rune) bool { return r == ':' })
+		title = strings.TrimSpace(strings.TrimSuffix(title, match[1]))
+	}
+	task.Title = title
+	return task
+}
+
+// parseOrgKeywords reads a #+TODO: line like TODO NEXT | DONE CANCELLED,
+// where the last keyword is the done one when there's no |.
+func parseOrgKeywords(line string) (map[string]bool, map[string]bool) {
+	todo := map[string]bool{}
+	done := map[string]bool{}
+	words := strings.Fields(line)
+	separator := len(words) - 1
+	for i, word := range words {
+		if word == "|" {
+			separator = i
+		}
+	}
+
+	for i, word := range words {
+		// fast access keys look like TODO(t)
+		if paren := strings.Index(word, "("); paren > 0 {
+			word = word[:paren]
+		}
+		switch {
+		case word == "|":
+		case i < separator:
+			todo[word] = true
+		default:
+			done[word] = true
+		}
+	}
+	return todo, done
+}
+
+// orgDate converts timestamps like 2026-10-20 Tue 10:00 +1w,
+// leaving out the day name and any repeater.
+func orgDate(timestamp string) string {
+	words := strings.Fields(timestamp)
+	if len(words) == 0 {
+		return ""
+	}
+	for _, word := range words[1:] {
+		if date, err := time.ParseInLocation("2006-01-02 15:04", words[0]+" "+word, time.Local); err == nil {
+			return date.Format(time.RFC3339)
+		}
+	}
+	return words[0]
+}
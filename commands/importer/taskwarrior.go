@@ -0,0 +1,138 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// taskwarriorIgnored are the fields of task export which only mean something
+// to Taskwarrior itself, so they aren't worth reporting as unmapped.
+var taskwarriorIgnored = map[string]bool{
+	"id":       true,
+	"uuid":     true,
+	"urgency":  true,
+	"modified": true,
+	"mask":     true,
+	"imask":    true,
+	"parent":   true,
+}
+
+// readTaskwarrior reads the output of task export,
+// which is either a JSON array or one JSON object per line.
+func readTaskwarrior(reader io.Reader) ([]task, error) {
+	contents, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := []map[string]interface{}{}
+	lines := []int{}
+	if trimmed := bytes.TrimSpace(contents); bytes.HasPrefix(trimmed, []byte("[")) {
+		if err := json.Unmarshal(trimmed, &objects); err != nil {
+			return nil, err
+		}
+		for i := range objects {
+			lines = append(lines, i+1)
+		}
+	} else {
+		for i, line := range bytes.Split(contents, []byte("\n")) {
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			object := map[string]interface{}{}
+			if err := json.Unmarshal(line, &object); err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			objects = append(objects, object)
+			lines = append(lines, i+1)
+		}
+	}
+
+	tasks := []task{}
+	for i, object := range objects {
+		status, _ := object["status"].(string)
+		if status == "deleted" {
+			continue
+		}
+
+		task := task{Line: lines[i], Fields: map[string]string{}}
+		for field, value := range object {
+			switch field {
+			case "status":
+				task.Done = status == "completed"
+			case "description":
+				task.Title = fmt.Sprint(value)
+			case "end":
+				task.Completed = taskwarriorDate(value)
+			case "due":
+				task.Due = taskwarriorDate(value)
+			case "priority":
+				task.Priority = fmt.Sprint(value)
+			case "project":
+				task.Projects = []string{fmt.Sprint(value)}
+			case "tags":
+				task.Tags = stringList(value)
+			case "annotations":
+				task.Body = taskwarriorAnnotations(value)
+			case "entry", "scheduled", "wait", "until":
+				task.Fields[field] = taskwarriorDate(value)
+			default:
+				if !taskwarriorIgnored[field] {
+					task.Fields[field] = strings.Join(stringList(value), ",")
+				}
+			}
+		}
+		// end is also set when a task is deleted,
+		// but it only means completed when the task is done
+		if !task.Done {
+			task.Completed = ""
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+// taskwarriorDate converts Taskwarrior's UTC timestamps,
+// and treats local midnight as a date without a time.
+func taskwarriorDate(value interface{}) string {
+	candidate, _ := value.(string)
+	date, err := time.Parse("20060102T150405Z", candidate)
+	if err != nil {
+		return candidate
+	}
+
+	date = date.Local()
+	if date.Hour() == 0 && date.Minute() == 0 && date.Second() == 0 {
+		return date.Format("2006-01-02")
+	}
+	return date.Format(time.RFC3339)
+}
+
+// taskwarriorAnnotations puts annotations in the body as a list.
+func taskwarriorAnnotations(value interface{}) string {
+	annotations, _ := value.([]interface{})
+	var builder strings.Builder
+	for _, annotation := range annotations {
+		annotation, _ := annotation.(map[string]interface{})
+		if description, ok := annotation["description"].(string); ok {
+			builder.WriteString("- " + description + "\n")
+		}
+	}
+	return builder.String()
+}
+
+func stringList(value interface{}) []string {
+	values, ok := value.([]interface{})
+	if !ok {
+		return []string{fmt.Sprint(value)}
+	}
+	list := make([]string, len(values))
+	for i, value := range values {
+		list[i] = fmt.Sprint(value)
+	}
+	return list
+}
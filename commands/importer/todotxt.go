@@ -0,0 +1,80 @@
+package importer
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	todoTxtPriority = regexp.MustCompile(`^\(([A-Z])\)$`)
+	todoTxtKeyValue = regexp.MustCompile(`^([^\s:]+):([^\s/][^\s]*)$`)
+)
+
+// readTodoTxt reads the format described at https://github.com/todotxt/todo.txt:
+// x 2026-10-19 (A) 2026-10-01 Call the landlord +flat @phone due:2026-10-20
+func readTodoTxt(reader io.Reader) ([]task, error) {
+	tasks := []task{}
+	scanner := bufio.NewScanner(reader)
+	line := 0
+	for scanner.Scan() {
+		line++
+		words := strings.Fields(scanner.Text())
+		if len(words) == 0 {
+			continue
+		}
+
+		task := task{Line: line, Fields: map[string]string{}}
+		if words[0] == "x" {
+			task.Done = true
+			words = words[1:]
+			if len(words) > 0 && isTodoTxtDate(words[0]) {
+				task.Completed = words[0]
+				words = words[1:]
+			}
+		}
+		if len(words) > 0 {
+			if match := todoTxtPriority.FindStringSubmatch(words[0]); match != nil {
+				task.Priority = match[1]
+				words = words[1:]
+			}
+		}
+		if len(words) > 0 && isTodoTxtDate(words[0]) {
+			task.Fields["created"] = words[0]
+			words = words[1:]
+		}
+
+		title := []string{}
+		for _, word := range words {
+			switch {
+			case len(word) > 1 && word[0] == '+':
+				task.Projects = append(task.Projects, word[1:])
+			case len(word) > 1 && word[0] == '@':
+				task.Contexts = append(task.Contexts, word[1:])
+			case todoTxtKeyValue.MatchString(word):
+				match := todoTxtKeyValue.FindStringSubmatch(word)
+				switch match[1] {
+				case "due":
+					task.Due = match[2]
+				case "pri":
+					// done tasks keep their priority as pri:A
+					task.Priority = match[2]
+				default:
+					task.Fields[match[1]] = match[2]
+				}
+			default:
+				title = append(title, word)
+			}
+		}
+		task.Title = strings.Join(title, " ")
+		tasks = append(tasks, task)
+	}
+	return tasks, scanner.Err()
+}
+
+func isTodoTxtDate(word string) bool {
+	_, err := time.Parse("2006-01-02", word)
+	return err == nil
+}
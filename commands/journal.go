@@ -0,0 +1,245 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jomei/notionapi"
+
+	"github.com/crockeo/notion-cli/config"
+	"github.com/crockeo/notion-cli/parse"
+)
+
+// JournalEntry records a single change we made to a page,
+// so that undo can put the previous value back.
+// archiving is journaled as a change to the "archived" property,
+// and creating a page as archived going from true to false.
+type JournalEntry struct {
+	Time     time.Time       `json:"time"`
+	Run      string          `json:"run"`
+	Command  string          `json:"command"`
+	PageID   string          `json:"page_id"`
+	Property string          `json:"property"`
+	Previous json.RawMessage `json:"previous"`
+	New      json.RawMessage `json:"new"`
+}
+
+// ArchivedProperty is the Property of entries which archive or restore a page.
+const ArchivedProperty = "archived"
+
+var (
+	journalLock sync.Mutex
+
+	// journalRun groups the entries written by one invocation,
+	// and journalCommand is that invocation's command.
+	journalRun     = time.Now().Format(time.RFC3339Nano)
+	journalCommand string
+)
+
+func journalPath() (string, error) {
+	return config.StatePath("journal.jsonl")
+}
+
+// ReadJournal returns every entry in the undo journal, oldest first.
+func ReadJournal() ([]JournalEntry, error) {
+	path, err := journalPath()
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return []JournalEntry{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	entries := []JournalEntry{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry JournalEntry
+		// a line we can't read shouldn't lose us the rest of the journal
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err == nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// WriteJournal replaces the undo journal,
+// e.g. once undo has reverted some of its entries.
+func WriteJournal(entries []JournalEntry) error {
+	path, err := journalPath()
+	if err != nil {
+		return err
+	}
+
+	contents := []byte{}
+	for _, entry := range entries {
+		bytes, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		contents = append(contents, bytes...)
+		contents = append(contents, '\n')
+	}
+
+	journalLock.Lock()
+	defer journalLock.Unlock()
+	return os.WriteFile(path, contents, 0600)
+}
+
+func appendJournal(entries []JournalEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	path, err := journalPath()
+	if err != nil {
+		return err
+	}
+
+	contents := []byte{}
+	for _, entry := range entries {
+		bytes, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		contents = append(contents, bytes...)
+		contents = append(contents, '\n')
+	}
+
+	// batch commands mutate from several goroutines,
+	// so we make sure that entries aren't interleaved
+	journalLock.Lock()
+	defer journalLock.Unlock()
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(contents)
+	return err
+}
+
+// journalUpdate records what request changed about page.
+// properties which page doesn't have a previous value for can't be undone,
+// so they're left out.
+func journalUpdate(page *notionapi.Page, request *notionapi.PageUpdateRequest) error {
+	now := time.Now()
+	entries := []JournalEntry{}
+	for propName, property := range request.Properties {
+		previousProperty, ok := page.Properties[propName]
+		if !ok {
+			continue
+		}
+		previous, err := journalValue(previousProperty)
+		if err != nil {
+			return err
+		}
+		new, err := journalValue(property)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, newJournalEntry(now, page.ID, propName, previous, new))
+	}
+
+	if request.Archived != page.Archived {
+		entries = append(entries, newJournalEntry(
+			now,
+			page.ID,
+			ArchivedProperty,
+			json.RawMessage(fmt.Sprint(page.Archived)),
+			json.RawMessage(fmt.Sprint(request.Archived)),
+		))
+	}
+	return appendJournal(entries)
+}
+
+// journalValue marshals a property so that it can be sent back as it is.
+// the API client doesn't round trip empty values or dates without times,
+// so those are written the way the API expects them.
+func journalValue(property notionapi.Property) (json.RawMessage, error) {
+	switch property := property.(type) {
+	case *notionapi.DateProperty:
+		if property.Date.Start == nil {
+			return json.RawMessage(`{"type":"date","date":null}`), nil
+		}
+		date := &parse.DateProperty{Type: notionapi.PropertyTypeDate}
+		start, _ := parse.FromNotionDate(property.Date.Start)
+		date.Date.Start = (*parse.TimelessDate)(&start)
+		if property.Date.End != nil {
+			end, _ := parse.FromNotionDate(property.Date.End)
+			date.Date.End = (*parse.TimelessDate)(&end)
+		}
+		return json.Marshal(date)
+	case *notionapi.SelectProperty:
+		if property.Select.Name == "" {
+			return json.RawMessage(`{"type":"select","select":null}`), nil
+		}
+	}
+	return json.Marshal(property)
+}
+
+func journalCreate(page *notionapi.Page) error {
+	return appendJournal([]JournalEntry{
+		newJournalEntry(time.Now(), page.ID, ArchivedProperty, json.RawMessage("true"), json.RawMessage("false")),
+	})
+}
+
+func newJournalEntry(now time.Time, pageID notionapi.ObjectID, propName string, previous, new json.RawMessage) JournalEntry {
+	return JournalEntry{
+		Time:     now,
+		Run:      journalRun,
+		Command:  journalCommand,
+		PageID:   string(pageID),
+		Property: propName,
+		Previous: previous,
+		New:      new,
+	}
+}
+
+// RawProperty sends a property value exactly as it was journaled.
+type RawProperty struct {
+	Type  notionapi.PropertyType
+	Value json.RawMessage
+}
+
+func (p RawProperty) GetType() notionapi.PropertyType {
+	return p.Type
+}
+
+func (p RawProperty) MarshalJSON() ([]byte, error) {
+	return p.Value, nil
+}
+
+// Revert puts back the previous value from a journal entry.
+// reverting isn't journaled itself,
+// instead undo takes the entries it reverted out of the journal.
+func Revert(client *notionapi.Client, entry JournalEntry) error {
+	request := &notionapi.PageUpdateRequest{Properties: notionapi.Properties{}}
+	if entry.Property == ArchivedProperty {
+		if err := json.Unmarshal(entry.Previous, &request.Archived); err != nil {
+			return err
+		}
+	} else {
+		var typed struct {
+			Type notionapi.PropertyType `json:"type"`
+		}
+		if err := json.Unmarshal(entry.Previous, &typed); err != nil {
+			return err
+		}
+		request.Properties[entry.Property] = RawProperty{Type: typed.Type, Value: entry.Previous}
+	}
+
+	if DryRun {
+		return printDryRun(dryRunEntry{Action: "update", PageID: entry.PageID, Request: request})
+	}
+	_, err := client.Page.Update(context.Background(), notionapi.PageID(entry.PageID), request)
+	return err
+}
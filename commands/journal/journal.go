@@ -0,0 +1,153 @@
+package journal
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jomei/notionapi"
+
+	"github.com/crockeo/notion-cli/commands"
+	"github.com/crockeo/notion-cli/commands/appendbody"
+	"github.com/crockeo/notion-cli/config"
+	"github.com/crockeo/notion-cli/database"
+	"github.com/crockeo/notion-cli/errors"
+	"github.com/crockeo/notion-cli/parse"
+)
+
+const defaultTitleFormat = "2006-01-02"
+
+var (
+	flags = flag.NewFlagSet("journal", flag.ContinueOnError)
+	date  = flags.String("date", "today", "Day to write to, e.g. yesterday.")
+)
+
+var Command = &commands.Command{
+	Name:    "journal",
+	Summary: "Append a note to today's page in the journal database.",
+	Usage:   "[flags] [text|-]",
+	Examples: []string{
+		"journal 'Shipped the importer, reviewing the sync PR next'",
+		"journal",
+		"journal -date yesterday -",
+	},
+	Flags: flags,
+	Run:   Journal,
+}
+
+func Journal(config *config.Config, client *notionapi.Client, args []string) error {
+	if config.Journal.DatabaseID == "" {
+		return errors.New(errors.KindConfig, "config.Journal.DatabaseID must be set to use journal")
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	dateProperty, err := parse.ParseDate(*date, today)
+	if err != nil || dateProperty == nil {
+		return errors.New(errors.KindUsage, "could not understand -date '%s'", *date)
+	}
+	start := time.Time(*dateProperty.Date.Start)
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+
+	var contents []byte
+	switch {
+	case len(args) == 0:
+		contents, err = commands.EditBody(nil)
+	case len(args) == 1 && args[0] == "-":
+		contents, err = io.ReadAll(os.Stdin)
+	default:
+		contents = []byte(strings.Join(args, " "))
+	}
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(string(contents)) == "" {
+		return errors.New(errors.KindUsage, "nothing to write")
+	}
+
+	// the rest of notion-cli works with config.DatabaseID,
+	// so we point a copy of the config at the journal instead
+	journalConfig := *config
+	journalConfig.DatabaseID = config.Journal.DatabaseID
+
+	page, err := getPage(&journalConfig, client, day)
+	if err != nil {
+		return err
+	}
+	return appendbody.AppendMarkdown(client, page, contents, now.Format("15:04"), false)
+}
+
+// getPage finds the journal page for day,
+// and creates it when there isn't one yet.
+func getPage(config *config.Config, client *notionapi.Client, day time.Time) (*notionapi.Page, error) {
+	db, err := database.GetSync(config, client)
+	if err != nil {
+		return nil, err
+	}
+	titleProperty, ok := database.TitleProperty(db)
+	if !ok {
+		return nil, errors.New(errors.KindConfig, "the journal database doesn't have a title property")
+	}
+
+	titleFormat := config.Journal.TitleFormat
+	if titleFormat == "" {
+		titleFormat = defaultTitleFormat
+	}
+	title := day.Format(titleFormat)
+
+	// the date is a better way to find the page than its title,
+	// since the title format might have changed since it was created
+	dateProperty := config.Journal.DateProperty
+	var pages []notionapi.Page
+	if dateProperty != "" {
+		if _, ok := db.Properties[dateProperty].(*notionapi.DatePropertyConfig); !ok {
+			return nil, errors.New(errors.KindConfig, "config.Journal.DateProperty is not a date in the journal database: %s", dateProperty)
+		}
+		pages, err = database.QueryDay(config, client, dateProperty, day)
+	} else {
+		pages, err = database.QueryAll(config, client, &notionapi.DatabaseQueryRequest{
+			PropertyFilter: &notionapi.PropertyFilter{
+				Property: titleProperty,
+				Text:     &notionapi.TextFilterCondition{Equals: title},
+			},
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(pages) > 0 {
+		return &pages[0], nil
+	}
+
+	titleValue, err := parse.ParseTitle(title)
+	if err != nil {
+		return nil, err
+	}
+	properties := notionapi.Properties{titleProperty: titleValue}
+	if dateProperty != "" {
+		properties[dateProperty] = &parse.DateProperty{
+			Date: parse.DateObject{Start: (*parse.TimelessDate)(&day)},
+		}
+	}
+
+	page, err := commands.CreatePage(client, &notionapi.PageCreateRequest{
+		Parent: notionapi.Parent{
+			Type:       notionapi.ParentTypeDatabaseID,
+			DatabaseID: notionapi.DatabaseID(db.ID),
+		},
+		Properties: properties,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if page == nil {
+		// during a dry run there's no page to append to,
+		// so the append is printed without an ID
+		return &notionapi.Page{}, nil
+	}
+	fmt.Fprintln(os.Stderr, "created", page.URL)
+	return page, nil
+}
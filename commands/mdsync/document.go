@@ -0,0 +1,153 @@
+package mdsync
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jomei/notionapi"
+	"gopkg.in/yaml.v2"
+
+	"github.com/crockeo/notion-cli/config"
+	"github.com/crockeo/notion-cli/database"
+	"github.com/crockeo/notion-cli/errors"
+	"github.com/crockeo/notion-cli/markdown"
+)
+
+// syncedTypes are the property types which parse.Property can write back,
+// the rest are left out of the frontmatter rather than being read-only in it.
+var syncedTypes = map[notionapi.PropertyType]bool{
+	notionapi.PropertyTypeTitle:       true,
+	notionapi.PropertyTypeRichText:    true,
+	notionapi.PropertyTypeNumber:      true,
+	notionapi.PropertyTypeSelect:      true,
+	notionapi.PropertyTypeMultiSelect: true,
+	notionapi.PropertyTypeDate:        true,
+	notionapi.PropertyTypeCheckbox:    true,
+	notionapi.PropertyTypeURL:         true,
+	notionapi.PropertyTypeEmail:       true,
+	notionapi.PropertyTypePhoneNumber: true,
+	notionapi.PropertyTypeRelation:    true,
+}
+
+// document is a page as a Markdown file:
+// frontmatter with the page's ID and properties, followed by its body.
+type document struct {
+	ID         string
+	URL        string
+	Properties map[string]string
+	Body       []byte
+}
+
+func newDocument(page *notionapi.Page, blocks []notionapi.Block) *document {
+	properties := map[string]string{}
+	for propName, property := range page.Properties {
+		if syncedTypes[property.GetType()] {
+			properties[propName] = database.FormatProperty(property)
+		}
+	}
+	return &document{
+		ID:         string(page.ID),
+		URL:        page.URL,
+		Properties: properties,
+		Body:       markdown.FromBlocks(blocks),
+	}
+}
+
+func parseDocument(contents []byte) (*document, error) {
+	frontmatter, body, ok := markdown.SplitFrontmatter(contents)
+	if !ok {
+		return nil, errors.New(errors.KindValidation, "missing frontmatter")
+	}
+
+	// values are read loosely, since someone editing the file
+	// is going to write Done: true rather than Done: "true"
+	var raw struct {
+		ID         string                 `yaml:"id"`
+		URL        string                 `yaml:"url"`
+		Properties map[string]interface{} `yaml:"properties"`
+	}
+	if err := yaml.Unmarshal(frontmatter, &raw); err != nil {
+		return nil, errors.Wrap(errors.KindValidation, err, "invalid frontmatter")
+	}
+
+	doc := &document{ID: raw.ID, URL: raw.URL, Properties: map[string]string{}, Body: body}
+	for propName, value := range raw.Properties {
+		doc.Properties[propName] = markdown.FrontmatterString(value)
+	}
+	return doc, nil
+}
+
+// marshal writes the frontmatter in the same order every time,
+// title first and then in the capture order,
+// so that pulling an unchanged page doesn't produce a diff.
+func (d *document) marshal(config *config.Config, db *notionapi.Database) ([]byte, error) {
+	properties := yaml.MapSlice{}
+	for _, propName := range propertyOrder(config, db, d.Properties) {
+		properties = append(properties, yaml.MapItem{Key: propName, Value: d.Properties[propName]})
+	}
+
+	frontmatter := yaml.MapSlice{}
+	if d.ID != "" {
+		frontmatter = append(frontmatter, yaml.MapItem{Key: "id", Value: d.ID})
+	}
+	if d.URL != "" {
+		frontmatter = append(frontmatter, yaml.MapItem{Key: "url", Value: d.URL})
+	}
+	frontmatter = append(frontmatter, yaml.MapItem{Key: "properties", Value: properties})
+
+	bytes, err := yaml.Marshal(frontmatter)
+	if err != nil {
+		return nil, err
+	}
+	return markdown.JoinFrontmatter(bytes, d.Body), nil
+}
+
+func propertyOrder(config *config.Config, db *notionapi.Database, properties map[string]string) []string {
+	order := []string{}
+	seen := map[string]bool{}
+	add := func(propName string) {
+		if _, ok := properties[propName]; ok && !seen[propName] {
+			order = append(order, propName)
+			seen[propName] = true
+		}
+	}
+
+	if titleProperty, ok := database.TitleProperty(db); ok {
+		add(titleProperty)
+	}
+	for _, propName := range config.Capture.Order {
+		add(propName)
+	}
+	rest := []string{}
+	for propName := range properties {
+		if !seen[propName] {
+			rest = append(rest, propName)
+		}
+	}
+	sort.Strings(rest)
+	for _, propName := range rest {
+		add(propName)
+	}
+	return order
+}
+
+var unsafeFilename = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slug turns a page title into a file name.
+func slug(title string) string {
+	name := strings.Trim(unsafeFilename.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	if name == "" {
+		name = "untitled"
+	}
+	if len(name) > 60 {
+		name = strings.TrimRight(name[:60], "-")
+	}
+	return name
+}
+
+// isNil catches the typed nils that parse.Property returns for empty values.
+func isNil(property notionapi.Property) bool {
+	return property == nil || reflect.ValueOf(property).Kind() == reflect.Ptr && reflect.ValueOf(property).IsNil()
+}
@@ -0,0 +1,410 @@
+package mdsync
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jomei/notionapi"
+
+	"github.com/crockeo/notion-cli/commands"
+	"github.com/crockeo/notion-cli/config"
+	"github.com/crockeo/notion-cli/database"
+	"github.com/crockeo/notion-cli/errors"
+	"github.com/crockeo/notion-cli/markdown"
+	"github.com/crockeo/notion-cli/parse"
+)
+
+var (
+	pullFlags = flag.NewFlagSet("pull", flag.ContinueOnError)
+	pullForce = pullFlags.Bool("force", false, "Overwrite files which were changed locally as well as in Notion.")
+
+	pushFlags = flag.NewFlagSet("push", flag.ContinueOnError)
+	pushForce = pushFlags.Bool("force", false, "Push files whose page was changed in Notion as well.")
+)
+
+var PullCommand = &commands.Command{
+	Name:     "pull",
+	Summary:  "Write every page in the database to a Markdown file in a directory.",
+	Usage:    "[-force] <dir>",
+	Examples: []string{"pull ~/notes/tasks", "pull -force ~/notes/tasks"},
+	Flags:    pullFlags,
+	Run:      Pull,
+}
+
+var PushCommand = &commands.Command{
+	Name:     "push",
+	Summary:  "Send the Markdown files changed since the last pull back to Notion.",
+	Usage:    "[-force] <dir>",
+	Examples: []string{"push ~/notes/tasks", "push --dry-run ~/notes/tasks"},
+	Flags:    pushFlags,
+	Run:      Push,
+}
+
+func Pull(config *config.Config, client *notionapi.Client, args []string) error {
+	dir, err := getDir(args)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	state, err := readState(dir, config.DatabaseID)
+	if err != nil {
+		return err
+	}
+
+	databaseChan, errChan := database.Get(config, client)
+	pages, err := database.QueryAll(config, client, nil)
+	if err != nil {
+		return err
+	}
+	db, err := database.Join(databaseChan, errChan)
+	if err != nil {
+		return err
+	}
+
+	files := map[string]bool{}
+	for _, page := range state.Pages {
+		files[page.File] = true
+	}
+
+	pulled, conflicts := 0, 0
+	seen := map[string]bool{}
+	for i := range pages {
+		page := &pages[i]
+		pageID := database.NormalizeID(string(page.ID))
+		seen[pageID] = true
+
+		synced := state.Pages[pageID]
+		if synced != nil {
+			contents, err := os.ReadFile(filepath.Join(dir, synced.File))
+			missing := os.IsNotExist(err)
+			if err != nil && !missing {
+				return err
+			}
+			localChanged := !missing && hash(contents) != synced.Hash
+			remoteChanged := !page.LastEditedTime.Equal(synced.LastEdited)
+			if !missing && !remoteChanged {
+				continue
+			}
+			if localChanged && !*pullForce {
+				fmt.Printf("conflict %s: changed locally and in Notion, skipping\n", synced.File)
+				conflicts++
+				continue
+			}
+		}
+
+		file := ""
+		if synced != nil {
+			file = synced.File
+		} else {
+			file = newFilename(dir, files, page)
+			files[file] = true
+		}
+
+		if commands.DryRun {
+			fmt.Println("would pull " + file)
+			continue
+		}
+		newState, err := writePage(config, client, db, dir, file, page)
+		if err != nil {
+			return errors.Wrap(errors.KindOf(err), err, "failed to pull %s", file)
+		}
+		state.Pages[pageID] = newState
+		fmt.Println("pulled " + file)
+		pulled++
+	}
+
+	for pageID, page := range state.Pages {
+		if !seen[pageID] {
+			fmt.Printf("gone %s: no longer in the database, leaving the file alone\n", page.File)
+			if !commands.DryRun {
+				delete(state.Pages, pageID)
+			}
+		}
+	}
+
+	if commands.DryRun {
+		return nil
+	}
+	if err := state.write(dir); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "pulled %d pages, %d conflicts\n", pulled, conflicts)
+	return nil
+}
+
+func Push(config *config.Config, client *notionapi.Client, args []string) error {
+	dir, err := getDir(args)
+	if err != nil {
+		return err
+	}
+	state, err := readState(dir, config.DatabaseID)
+	if err != nil {
+		return err
+	}
+	db, err := database.GetSync(config, client)
+	if err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	files := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".md") {
+			files = append(files, entry.Name())
+		}
+	}
+	sort.Strings(files)
+
+	pushed, conflicts, failed := 0, 0, 0
+	for _, file := range files {
+		contents, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			return err
+		}
+		doc, err := parseDocument(contents)
+		if err != nil {
+			fmt.Printf("invalid %s: %s\n", file, err.Error())
+			failed++
+			continue
+		}
+
+		pageID := database.NormalizeID(doc.ID)
+		if pageID == "" {
+			if syncedID, ok := state.pageIDForFile(file); ok {
+				pageID = syncedID
+			}
+		}
+		synced := state.Pages[pageID]
+		if synced != nil && synced.Hash == hash(contents) {
+			continue
+		}
+
+		var page *notionapi.Page
+		if pageID == "" {
+			page, err = createPage(client, db, doc)
+		} else {
+			page, err = pushPage(client, db, pageID, synced, doc)
+		}
+		if err == errConflict {
+			fmt.Printf("conflict %s: changed locally and in Notion, skipping\n", file)
+			conflicts++
+			continue
+		} else if err != nil {
+			fmt.Printf("failed %s: %s\n", file, err.Error())
+			failed++
+			continue
+		}
+
+		if commands.DryRun {
+			continue
+		}
+		// the file is rewritten from Notion, so that it has the page's ID
+		// and values like "tomorrow" are replaced with what they meant
+		newState, err := writePage(config, client, db, dir, file, page)
+		if err != nil {
+			return errors.Wrap(errors.KindOf(err), err, "failed to refresh %s", file)
+		}
+		state.Pages[database.NormalizeID(string(page.ID))] = newState
+		fmt.Println("pushed " + file)
+		pushed++
+	}
+
+	if commands.DryRun {
+		return nil
+	}
+	if err := state.write(dir); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "pushed %d pages, %d conflicts, %d failed\n", pushed, conflicts, failed)
+	if failed > 0 {
+		return errors.New(errors.KindValidation, "%d files could not be pushed", failed)
+	}
+	return nil
+}
+
+var errConflict = errors.New(errors.KindValidation, "conflict")
+
+// pushPage sends the properties and body of doc which differ from the page.
+// it returns the page as it is after the update.
+func pushPage(client *notionapi.Client, db *notionapi.Database, pageID string, synced *pageState, doc *document) (*notionapi.Page, error) {
+	page, err := client.Page.Get(context.Background(), notionapi.PageID(pageID))
+	if err != nil {
+		return nil, err
+	}
+	// without a record of the last sync we can't tell who changed what
+	if (synced == nil || !page.LastEditedTime.Equal(synced.LastEdited)) && !*pushForce {
+		return nil, errConflict
+	}
+
+	properties, err := getProperties(db, doc, page)
+	if err != nil {
+		return nil, err
+	}
+	// everything is converted and checked before the first update,
+	// so that a body we can't push doesn't leave the push half done
+	blocks, err := database.GetBlocks(client, notionapi.PageID(page.ID))
+	if err != nil {
+		return nil, err
+	}
+	newBlocks, replace, err := convertBody(page, blocks, doc.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(properties) > 0 {
+		if _, err := commands.UpdatePage(client, page, &notionapi.PageUpdateRequest{Properties: properties}); err != nil {
+			return nil, err
+		}
+	}
+	if replace {
+		if err := replaceBody(client, page, blocks, newBlocks); err != nil {
+			return nil, err
+		}
+	}
+
+	if commands.DryRun {
+		return page, nil
+	}
+	return client.Page.Get(context.Background(), notionapi.PageID(page.ID))
+}
+
+// convertBody converts body to the blocks which replace the page's body,
+// and replace is false when the body is unchanged.
+// the Markdown conversion doesn't support everything a page can contain,
+// so we only replace it when neither side would lose anything along the way.
+func convertBody(page *notionapi.Page, blocks []notionapi.Block, body []byte) ([]notionapi.Block, bool, error) {
+	if bytes.Equal(bytes.TrimSpace(markdown.FromBlocks(blocks)), bytes.TrimSpace(body)) {
+		return nil, false, nil
+	}
+	if !markdown.Lossless(blocks) {
+		fmt.Fprintf(os.Stderr, "%s: the page has blocks which can't be written as Markdown, leaving its body alone\n", database.PageTitle(page))
+		return nil, false, nil
+	}
+	newBlocks, err := markdown.ToBlocks(body)
+	if err != nil {
+		return nil, false, errors.Wrap(errors.KindValidation, err, "could not convert the body to blocks")
+	}
+	if !bytes.Equal(bytes.TrimSpace(markdown.FromBlocks(newBlocks)), bytes.TrimSpace(body)) {
+		fmt.Fprintf(os.Stderr, "%s: the body uses Markdown which can't be converted to blocks, leaving it alone\n", database.PageTitle(page))
+		return nil, false, nil
+	}
+	return newBlocks, true, nil
+}
+
+// replaceBody appends the new body before deleting the old one,
+// so that a failure never leaves the page without either of them.
+// block deletes aren't journaled, which is why the old blocks
+// are only deleted once every new one is in place.
+func replaceBody(client *notionapi.Client, page *notionapi.Page, blocks []notionapi.Block, newBlocks []notionapi.Block) error {
+	if err := commands.AppendBlocks(client, notionapi.BlockID(page.ID), newBlocks); err != nil {
+		return errors.Wrap(errors.KindOf(err), err, "failed to append the new body, the old one was kept but part of the new one may follow it")
+	}
+	for _, block := range blocks {
+		if err := commands.DeleteBlock(client, block.GetID()); err != nil {
+			return errors.Wrap(errors.KindOf(err), err, "failed to delete the old body, part of it is still before the new one")
+		}
+	}
+	return nil
+}
+
+func createPage(client *notionapi.Client, db *notionapi.Database, doc *document) (*notionapi.Page, error) {
+	properties, err := getProperties(db, doc, &notionapi.Page{})
+	if err != nil {
+		return nil, err
+	}
+	children, err := markdown.ToBlocks(doc.Body)
+	if err != nil {
+		return nil, errors.Wrap(errors.KindValidation, err, "could not convert the body to blocks")
+	}
+
+	page, err := commands.CreatePage(client, &notionapi.PageCreateRequest{
+		Parent: notionapi.Parent{
+			Type:       notionapi.ParentTypeDatabaseID,
+			DatabaseID: notionapi.DatabaseID(db.ID),
+		},
+		Properties: properties,
+		Children:   children,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// getProperties parses the frontmatter values which differ from page.
+func getProperties(db *notionapi.Database, doc *document, page *notionapi.Page) (notionapi.Properties, error) {
+	properties := notionapi.Properties{}
+	for propName, value := range doc.Properties {
+		propConfig, ok := db.Properties[propName]
+		if !ok {
+			return nil, errors.New(errors.KindValidation, "property '%s' does not exist in the database", propName)
+		}
+		if !syncedTypes[notionapi.PropertyType(propConfig.GetType())] {
+			continue
+		}
+		if database.FormatProperty(page.Properties[propName]) == value {
+			continue
+		}
+
+		property, err := parse.Property(propName, propConfig, value)
+		if err != nil {
+			return nil, errors.Wrap(errors.KindValidation, err, "invalid value for '%s'", propName)
+		}
+		if isNil(property) {
+			fmt.Fprintf(os.Stderr, "%s: can't clear '%s', leaving it alone\n", database.PageTitle(page), propName)
+			continue
+		}
+		properties[propName] = property
+	}
+	return properties, nil
+}
+
+// writePage renders page into file, and returns what the state should
+// remember about it.
+func writePage(config *config.Config, client *notionapi.Client, db *notionapi.Database, dir string, file string, page *notionapi.Page) (*pageState, error) {
+	blocks, err := database.GetBlocks(client, notionapi.PageID(page.ID))
+	if err != nil {
+		return nil, err
+	}
+	contents, err := newDocument(page, blocks).marshal(config, db)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, file), contents, 0644); err != nil {
+		return nil, err
+	}
+	return &pageState{
+		File:       file,
+		Hash:       hash(contents),
+		LastEdited: page.LastEditedTime,
+	}, nil
+}
+
+// newFilename picks a file for a page we haven't pulled before,
+// and adds part of its ID when another page already has the same title.
+func newFilename(dir string, files map[string]bool, page *notionapi.Page) string {
+	name := slug(database.PageTitle(page))
+	file := name + ".md"
+	if _, err := os.Stat(filepath.Join(dir, file)); files[file] || err == nil {
+		file = name + "-" + database.NormalizeID(string(page.ID))[:8] + ".md"
+	}
+	return file
+}
+
+func getDir(args []string) (string, error) {
+	if len(args) != 1 {
+		return "", errors.New(errors.KindUsage, "expected a directory")
+	}
+	return args[0], nil
+}
@@ -0,0 +1,72 @@
+package mdsync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/crockeo/notion-cli/errors"
+)
+
+const stateFile = ".notion-sync.json"
+
+// state remembers what every synced file looked like the last time
+// it matched Notion, which is how pull and push tell who changed what.
+type state struct {
+	DatabaseID string                `json:"database"`
+	Pages      map[string]*pageState `json:"pages"`
+}
+
+type pageState struct {
+	File       string    `json:"file"`
+	Hash       string    `json:"hash"`
+	LastEdited time.Time `json:"last_edited_time"`
+}
+
+func readState(dir string, databaseID string) (*state, error) {
+	contents, err := os.ReadFile(filepath.Join(dir, stateFile))
+	if os.IsNotExist(err) {
+		return &state{DatabaseID: databaseID, Pages: map[string]*pageState{}}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	state := &state{}
+	if err := json.Unmarshal(contents, state); err != nil {
+		return nil, errors.Wrap(errors.KindValidation, err, "could not read %s", filepath.Join(dir, stateFile))
+	}
+	if state.Pages == nil {
+		state.Pages = map[string]*pageState{}
+	}
+	if state.DatabaseID != "" && state.DatabaseID != databaseID {
+		return nil, errors.New(errors.KindConfig, "%s is synced with a different database: %s", dir, state.DatabaseID)
+	}
+	state.DatabaseID = databaseID
+	return state, nil
+}
+
+func (s *state) write(dir string) error {
+	bytes, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, stateFile), append(bytes, '\n'), 0644)
+}
+
+// pageIDForFile finds the page that a file was last synced with.
+func (s *state) pageIDForFile(file string) (string, bool) {
+	for pageID, page := range s.Pages {
+		if page.File == file {
+			return pageID, true
+		}
+	}
+	return "", false
+}
+
+func hash(contents []byte) string {
+	sum := sha256.Sum256(contents)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jomei/notionapi"
+
+	"github.com/crockeo/notion-cli/config"
+	"github.com/crockeo/notion-cli/database"
+	"github.com/crockeo/notion-cli/errors"
+)
+
+// FindPage finds the page someone means by candidate,
+// which is a page ID, a Notion URL,
+// or the title of a page in the configured database.
+func FindPage(config *config.Config, client *notionapi.Client, candidate string) (*notionapi.Page, error) {
+	if pageID, ok := database.ParsePageID(candidate); ok {
+		return client.Page.Get(context.Background(), pageID)
+	}
+
+	db, err := database.GetCached(config, client, -1)
+	if err != nil {
+		return nil, err
+	}
+	titleProperty, ok := database.TitleProperty(db)
+	if !ok {
+		return nil, errors.New(errors.KindConfig, "the database doesn't have a title property")
+	}
+
+	pages, err := database.QueryAll(config, client, &notionapi.DatabaseQueryRequest{
+		PropertyFilter: &notionapi.PropertyFilter{
+			Property: titleProperty,
+			Text:     &notionapi.TextFilterCondition{Equals: candidate},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the API matches titles exactly,
+	// so we only have to worry about there being more than one
+	switch len(pages) {
+	case 0:
+		return nil, errors.New(errors.KindNotFound, "could not find a page titled '%s'", candidate)
+	case 1:
+		return &pages[0], nil
+	}
+	ids := []string{}
+	for _, page := range pages {
+		ids = append(ids, string(page.ID))
+	}
+	return nil, errors.New(errors.KindValidation, "more than one page is titled '%s', use one of their IDs instead: %s", candidate, strings.Join(ids, ", "))
+}
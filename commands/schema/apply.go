@@ -0,0 +1,427 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jomei/notionapi"
+	"github.com/manifoldco/promptui"
+	"gopkg.in/yaml.v2"
+
+	"github.com/crockeo/notion-cli/commands"
+	"github.com/crockeo/notion-cli/config"
+	"github.com/crockeo/notion-cli/database"
+	"github.com/crockeo/notion-cli/errors"
+)
+
+// schemaFile is what apply reads, e.g.
+//
+//	properties:
+//	  Name:
+//	    type: title
+//	  Status:
+//	    type: select
+//	    options:
+//	      - Todo
+//	      - {name: Done, color: green}
+//	  Estimate:
+//	    type: number
+//	    format: dollar
+type schemaFile struct {
+	Properties map[string]propertySchema `yaml:"properties"`
+}
+
+type propertySchema struct {
+	Type    string         `yaml:"type"`
+	Format  string         `yaml:"format"`
+	Options []optionSchema `yaml:"options"`
+}
+
+// optionSchema is either just a name, or a name and a color.
+type optionSchema struct {
+	Name  string `yaml:"name"`
+	Color string `yaml:"color"`
+}
+
+func (o *optionSchema) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if err := unmarshal(&o.Name); err == nil {
+		return nil
+	}
+	type plain optionSchema
+	return unmarshal((*plain)(o))
+}
+
+// creatableTypes are the types apply can create or convert a property to.
+// relations, rollups and formulas need more than a type,
+// so they can only be declared once they exist.
+var creatableTypes = map[string]bool{
+	"title":            true,
+	"rich_text":        true,
+	"number":           true,
+	"select":           true,
+	"multi_select":     true,
+	"date":             true,
+	"people":           true,
+	"files":            true,
+	"checkbox":         true,
+	"url":              true,
+	"email":            true,
+	"phone_number":     true,
+	"created_time":     true,
+	"created_by":       true,
+	"last_edited_time": true,
+	"last_edited_by":   true,
+}
+
+var colors = map[string]bool{
+	"default": true,
+	"gray":    true,
+	"brown":   true,
+	"orange":  true,
+	"yellow":  true,
+	"green":   true,
+	"blue":    true,
+	"purple":  true,
+	"pink":    true,
+	"red":     true,
+}
+
+// Apply updates the database to match a schema file.
+// properties which aren't in the file are left alone unless -prune is set.
+// renames can't be told apart from an addition and a removal,
+// so those should be done in Notion (and followed with schema diff -patch).
+func Apply(config *config.Config, client *notionapi.Client, args []string) error {
+	if len(args) != 1 {
+		return errors.New(errors.KindUsage, "expected a schema file, like 'schema apply schema.yaml'")
+	}
+	file, err := readSchemaFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	db, err := database.GetCached(config, client, 0)
+	if err != nil {
+		return err
+	}
+	formats := map[string]string{}
+	if declaresFormats(file) {
+		if formats, err = database.NumberFormats(config, client); err != nil {
+			return err
+		}
+	}
+	changes, properties, err := plan(db, formats, file, *prune)
+	if err != nil {
+		return err
+	}
+	if len(properties) == 0 {
+		fmt.Printf("the database already matches %s\n", args[0])
+		return nil
+	}
+
+	for _, change := range changes {
+		fmt.Println(change)
+	}
+	if !*yes && !commands.DryRun {
+		prompt := promptui.Prompt{
+			Label:     fmt.Sprintf("Update %d properties", len(properties)),
+			IsConfirm: true,
+		}
+		if _, err := prompt.Run(); err != nil {
+			return err
+		}
+	}
+
+	updated, err := commands.UpdateDatabase(client, notionapi.DatabaseID(config.DatabaseID), &notionapi.DatabaseUpdateRequest{
+		Properties: properties,
+	})
+	if err != nil {
+		return err
+	}
+	if updated == nil {
+		return nil
+	}
+	// shell completion and capture should see the new schema right away
+	if err := database.WriteCache(config, updated); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to refresh the schema cache: %s\n", err.Error())
+	}
+	fmt.Printf("updated %d properties\n", len(properties))
+	return nil
+}
+
+func readSchemaFile(path string) (*schemaFile, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(errors.KindUsage, err, "failed to read the schema")
+	}
+	file := &schemaFile{}
+	if err := yaml.UnmarshalStrict(contents, file); err != nil {
+		return nil, errors.Wrap(errors.KindValidation, err, "failed to parse %s", path)
+	}
+
+	titles := 0
+	for propName, property := range file.Properties {
+		if property.Type == "" {
+			return nil, errors.New(errors.KindValidation, "%s: missing a type", propName)
+		}
+		if property.Type == "title" {
+			titles++
+		}
+		if property.Format != "" && property.Type != "number" {
+			return nil, errors.New(errors.KindValidation, "%s: only numbers have a format", propName)
+		}
+		if len(property.Options) > 0 && property.Type != "select" && property.Type != "multi_select" {
+			return nil, errors.New(errors.KindValidation, "%s: only selects and multi-selects have options", propName)
+		}
+		seen := map[string]bool{}
+		for _, option := range property.Options {
+			if option.Name == "" {
+				return nil, errors.New(errors.KindValidation, "%s: options need a name", propName)
+			}
+			if strings.Contains(option.Name, ",") {
+				return nil, errors.New(errors.KindValidation, "%s: option '%s' can't contain a comma", propName, option.Name)
+			}
+			if seen[option.Name] {
+				return nil, errors.New(errors.KindValidation, "%s: option '%s' is listed twice", propName, option.Name)
+			}
+			seen[option.Name] = true
+			if option.Color != "" && !colors[option.Color] {
+				return nil, errors.New(errors.KindValidation, "%s: option '%s' has an unknown color '%s'", propName, option.Name, option.Color)
+			}
+		}
+	}
+	if titles > 1 {
+		return nil, errors.New(errors.KindValidation, "a database has exactly one title property, but %s declares %d", path, titles)
+	}
+	return file, nil
+}
+
+func declaresFormats(file *schemaFile) bool {
+	for _, property := range file.Properties {
+		if property.Format != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// plan works out the changes that bring db in line with file,
+// as both a description for people and the request's properties.
+// formats are the current number formats, see database.NumberFormats.
+func plan(db *notionapi.Database, formats map[string]string, file *schemaFile, prune bool) ([]string, notionapi.PropertyConfigs, error) {
+	changes := []string{}
+	properties := notionapi.PropertyConfigs{}
+
+	names := make([]string, 0, len(file.Properties))
+	for propName := range file.Properties {
+		names = append(names, propName)
+	}
+	sort.Strings(names)
+
+	titleProp, _ := database.TitleProperty(db)
+	for _, propName := range names {
+		property := file.Properties[propName]
+		current, exists := db.Properties[propName]
+		if property.Type == "title" && propName != titleProp {
+			return nil, nil, errors.New(errors.KindValidation, "%s: the title property is '%s', rename it in Notion or in the schema", propName, titleProp)
+		}
+
+		if !exists {
+			if !creatableTypes[property.Type] {
+				return nil, nil, errors.New(errors.KindValidation, "%s: apply can't create %s properties, add it in Notion first", propName, property.Type)
+			}
+			properties[propName] = newPropertyConfig(property, nil)
+			changes = append(changes, "+ "+describe(propName, property))
+			continue
+		}
+
+		currentType := string(current.GetType())
+		if currentType != property.Type {
+			if propName == titleProp {
+				return nil, nil, errors.New(errors.KindValidation, "%s: the title property can't change its type", propName)
+			}
+			if !creatableTypes[property.Type] {
+				return nil, nil, errors.New(errors.KindValidation, "%s: apply can't convert properties to %s, change it in Notion", propName, property.Type)
+			}
+			options := currentOptions(current)
+			if len(property.Options) > 0 {
+				options, _ = mergeOptions(options, property.Options, prune)
+			}
+			properties[propName] = newPropertyConfig(property, options)
+			changes = append(changes, fmt.Sprintf("~ %s: %s -> %s", propName, currentType, describe("", property)))
+			continue
+		}
+
+		switch current := current.(type) {
+		case *notionapi.NumberPropertyConfig:
+			if property.Format != "" && property.Format != formats[propName] {
+				properties[propName] = newPropertyConfig(property, nil)
+				changes = append(changes, fmt.Sprintf("~ %s format: %s -> %s", propName, formats[propName], property.Format))
+			}
+
+		case *notionapi.SelectPropertyConfig, *notionapi.MultiSelectPropertyConfig:
+			if len(property.Options) == 0 {
+				continue
+			}
+			options, optionChanges := mergeOptions(currentOptions(current), property.Options, prune)
+			if len(optionChanges) > 0 {
+				properties[propName] = newPropertyConfig(property, options)
+				changes = append(changes, fmt.Sprintf("~ %s options: %s", propName, strings.Join(optionChanges, ", ")))
+			}
+		}
+	}
+
+	if prune {
+		currentNames := make([]string, 0, len(db.Properties))
+		for propName := range db.Properties {
+			currentNames = append(currentNames, propName)
+		}
+		sort.Strings(currentNames)
+		for _, propName := range currentNames {
+			if _, ok := file.Properties[propName]; ok || propName == titleProp {
+				continue
+			}
+			// a nil config comes out as null, which deletes the property
+			properties[propName] = nil
+			changes = append(changes, fmt.Sprintf("- %s (%s)", propName, db.Properties[propName].GetType()))
+		}
+	}
+	return changes, properties, nil
+}
+
+// newPropertyConfig builds the config for a property of property's type.
+// options, if given, are the complete list of options to send,
+// otherwise they come from the schema.
+func newPropertyConfig(property propertySchema, options []notionapi.Option) notionapi.PropertyConfig {
+	propType := notionapi.PropertyConfigType(property.Type)
+	if options == nil {
+		for _, option := range property.Options {
+			options = append(options, notionapi.Option{Name: option.Name, Color: notionapi.Color(option.Color)})
+		}
+	}
+
+	switch propType {
+	case notionapi.PropertyConfigTypeNumber:
+		format := notionapi.FormatType(property.Format)
+		if format == "" {
+			format = notionapi.FormatNumber
+		}
+		return &numberPropertyConfig{format: format}
+	case notionapi.PropertyConfigTypeSelect:
+		return &notionapi.SelectPropertyConfig{Type: propType, Select: notionapi.Select{Options: nonNil(options)}}
+	case notionapi.PropertyConfigTypeMultiSelect:
+		return &notionapi.MultiSelectPropertyConfig{Type: propType, MultiSelect: notionapi.Select{Options: nonNil(options)}}
+	}
+	return &emptyPropertyConfig{propType: propType}
+}
+
+// emptyPropertyConfig is a property of a type that has no settings,
+// which the API expects as e.g. {"date": {}}.
+type emptyPropertyConfig struct {
+	propType notionapi.PropertyConfigType
+}
+
+func (p *emptyPropertyConfig) GetType() notionapi.PropertyConfigType {
+	return p.propType
+}
+
+func (p *emptyPropertyConfig) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf(`{"type":%q,%q:{}}`, p.propType, p.propType)), nil
+}
+
+// numberPropertyConfig is a number property with its format,
+// which notionapi.NumberPropertyConfig writes in the wrong place.
+// the API expects {"number": {"format": "dollar"}}.
+type numberPropertyConfig struct {
+	format notionapi.FormatType
+}
+
+func (p *numberPropertyConfig) GetType() notionapi.PropertyConfigType {
+	return notionapi.PropertyConfigTypeNumber
+}
+
+func (p *numberPropertyConfig) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"type":   notionapi.PropertyConfigTypeNumber,
+		"number": map[string]notionapi.FormatType{"format": p.format},
+	})
+}
+
+func nonNil(options []notionapi.Option) []notionapi.Option {
+	if options == nil {
+		return []notionapi.Option{}
+	}
+	return options
+}
+
+func currentOptions(propConfig notionapi.PropertyConfig) []notionapi.Option {
+	switch propConfig := propConfig.(type) {
+	case *notionapi.SelectPropertyConfig:
+		return propConfig.Select.Options
+	case *notionapi.MultiSelectPropertyConfig:
+		return propConfig.MultiSelect.Options
+	}
+	return nil
+}
+
+// mergeOptions lists the options to send so that the property
+// ends up with the declared ones. existing options are sent back as they are,
+// since the API can't recolor them, and are only dropped when pruning.
+func mergeOptions(current []notionapi.Option, declared []optionSchema, prune bool) ([]notionapi.Option, []string) {
+	existing := map[string]notionapi.Option{}
+	for _, option := range current {
+		existing[option.Name] = option
+	}
+
+	options := []notionapi.Option{}
+	changes := []string{}
+	wanted := map[string]bool{}
+	for _, option := range declared {
+		wanted[option.Name] = true
+		if currentOption, ok := existing[option.Name]; ok {
+			if option.Color != "" && option.Color != string(currentOption.Color) {
+				fmt.Fprintf(os.Stderr, "warning: '%s' is %s, and the API can't change the color of an existing option\n", option.Name, currentOption.Color)
+			}
+			options = append(options, currentOption)
+			continue
+		}
+		options = append(options, notionapi.Option{Name: option.Name, Color: notionapi.Color(option.Color)})
+		if option.Color != "" {
+			changes = append(changes, fmt.Sprintf("+%s (%s)", option.Name, option.Color))
+		} else {
+			changes = append(changes, "+"+option.Name)
+		}
+	}
+
+	for _, option := range current {
+		if wanted[option.Name] {
+			continue
+		}
+		if prune {
+			changes = append(changes, "-"+option.Name)
+		} else {
+			options = append(options, option)
+		}
+	}
+	return options, changes
+}
+
+func describe(propName string, property propertySchema) string {
+	parts := []string{property.Type}
+	if property.Format != "" {
+		parts = append(parts, "format "+property.Format)
+	}
+	if len(property.Options) > 0 {
+		names := make([]string, len(property.Options))
+		for i, option := range property.Options {
+			names[i] = option.Name
+		}
+		parts = append(parts, "options "+strings.Join(names, ", "))
+	}
+	if propName == "" {
+		return strings.Join(parts, ", ")
+	}
+	return fmt.Sprintf("%s (%s)", propName, strings.Join(parts, ", "))
+}
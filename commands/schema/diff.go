@@ -0,0 +1,243 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jomei/notionapi"
+
+	"github.com/crockeo/notion-cli/commands/dump"
+	"github.com/crockeo/notion-cli/config"
+	"github.com/crockeo/notion-cli/database"
+	"github.com/crockeo/notion-cli/errors"
+)
+
+// Diff compares the database against a snapshot taken with dump,
+// and points out the places in the config which name properties
+// that no longer exist.
+func Diff(config *config.Config, client *notionapi.Client, args []string) error {
+	if len(args) != 1 {
+		return errors.New(errors.KindUsage, "expected a snapshot from dump, like 'schema diff schema.json'")
+	}
+	snapshot, err := readSnapshot(args[0])
+	if err != nil {
+		return err
+	}
+
+	db, err := database.GetCached(config, client, 0)
+	if err != nil {
+		return err
+	}
+	current := dump.NewInfo(config, db)
+
+	changes, renames, byID := diffInfo(snapshot, current)
+	if !byID {
+		fmt.Fprintln(os.Stderr, "warning: the snapshot has no property IDs, so renames show up as a removal and an addition. take a new one with dump")
+	}
+	for _, change := range changes {
+		fmt.Println(change)
+	}
+
+	stale := staleReferences(config, db)
+	if len(stale) > 0 && *patch && len(renames) > 0 {
+		if stale, err = patchConfig(db, renames); err != nil {
+			return err
+		}
+	}
+	for _, reference := range stale {
+		hint := ""
+		if newName, ok := renames[reference.name]; ok {
+			hint = fmt.Sprintf(", which is now '%s'", newName)
+		}
+		fmt.Printf("config: %s names '%s'%s\n", reference.where, reference.name, hint)
+	}
+
+	if len(changes) == 0 && len(stale) == 0 {
+		fmt.Fprintln(os.Stderr, "no changes")
+	} else if len(changes) > 0 {
+		fmt.Fprintf(os.Stderr, "run 'dump > %s' to take a new snapshot\n", args[0])
+	}
+	if *exitCode && (len(changes) > 0 || len(stale) > 0) {
+		return errors.New(errors.KindValidation, "the schema has drifted from %s", args[0])
+	}
+	return nil
+}
+
+func readSnapshot(path string) (dump.NotionCliInfo, error) {
+	snapshot := dump.NotionCliInfo{}
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return snapshot, errors.Wrap(errors.KindUsage, err, "failed to read the snapshot")
+	}
+	if err := json.Unmarshal(contents, &snapshot); err != nil {
+		return snapshot, errors.Wrap(errors.KindValidation, err, "%s is not a snapshot from dump", path)
+	}
+	return snapshot, nil
+}
+
+// diffInfo describes how the schema went from old to new.
+// properties are matched up by their IDs, so that a rename
+// isn't mistaken for a removal and an addition.
+// renames maps old names to new ones, and byID is false
+// for old snapshots which don't have IDs to match by.
+func diffInfo(old dump.NotionCliInfo, new dump.NotionCliInfo) (changes []string, renames map[string]string, byID bool) {
+	byID = true
+	for _, propInfo := range old.Properties {
+		if propInfo.ID == "" {
+			byID = false
+		}
+	}
+
+	newNames := map[string]string{}
+	for propName, propInfo := range new.Properties {
+		if byID {
+			newNames[propInfo.ID] = propName
+		} else {
+			newNames[propName] = propName
+		}
+	}
+
+	renames = map[string]string{}
+	matched := map[string]bool{}
+	for _, oldName := range sortedNames(old.Properties) {
+		oldInfo := old.Properties[oldName]
+		key := oldName
+		if byID {
+			key = oldInfo.ID
+		}
+		newName, ok := newNames[key]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("- %s (%s)", oldName, oldInfo.Type))
+			continue
+		}
+		matched[newName] = true
+		newInfo := new.Properties[newName]
+
+		if newName != oldName {
+			renames[oldName] = newName
+			changes = append(changes, fmt.Sprintf("~ %s -> %s", oldName, newName))
+		}
+		if newInfo.Type != oldInfo.Type {
+			changes = append(changes, fmt.Sprintf("~ %s: %s -> %s", newName, oldInfo.Type, newInfo.Type))
+		}
+		if added, removed := diffOptions(oldInfo.Options, newInfo.Options); len(added) > 0 || len(removed) > 0 {
+			options := []string{}
+			for _, option := range added {
+				options = append(options, "+"+option)
+			}
+			for _, option := range removed {
+				options = append(options, "-"+option)
+			}
+			changes = append(changes, fmt.Sprintf("~ %s options: %s", newName, strings.Join(options, ", ")))
+		}
+	}
+
+	for _, newName := range sortedNames(new.Properties) {
+		if !matched[newName] {
+			changes = append(changes, fmt.Sprintf("+ %s (%s)", newName, new.Properties[newName].Type))
+		}
+	}
+	return changes, renames, byID
+}
+
+func diffOptions(old []string, new []string) (added []string, removed []string) {
+	oldOptions := map[string]bool{}
+	for _, option := range old {
+		oldOptions[option] = true
+	}
+	newOptions := map[string]bool{}
+	for _, option := range new {
+		newOptions[option] = true
+		if !oldOptions[option] {
+			added = append(added, option)
+		}
+	}
+	for _, option := range old {
+		if !newOptions[option] {
+			removed = append(removed, option)
+		}
+	}
+	return added, removed
+}
+
+func sortedNames(properties map[string]dump.PropInfo) []string {
+	names := make([]string, 0, len(properties))
+	for propName := range properties {
+		names = append(names, propName)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// reference is somewhere the config names a property.
+type reference struct {
+	where string
+	name  string
+}
+
+// staleReferences finds the places in the config
+// which name a property that isn't in the database.
+func staleReferences(config *config.Config, db *notionapi.Database) []reference {
+	references := []reference{}
+	add := func(where string, name string) {
+		if name != "" {
+			references = append(references, reference{where: where, name: name})
+		}
+	}
+
+	addDefaults := func(where string, defaults map[string]string) {
+		names := make([]string, 0, len(defaults))
+		for propName := range defaults {
+			names = append(names, propName)
+		}
+		sort.Strings(names)
+		for _, propName := range names {
+			add(where, propName)
+		}
+	}
+	addList := func(where string, propNames []string) {
+		for _, propName := range propNames {
+			add(where, propName)
+		}
+	}
+
+	addDefaults("capture.defaults", config.Capture.Defaults)
+	addList("capture.order", config.Capture.Order)
+	sigils := make([]string, 0, len(config.Capture.Sigils))
+	for sigil := range config.Capture.Sigils {
+		sigils = append(sigils, sigil)
+	}
+	sort.Strings(sigils)
+	for _, sigil := range sigils {
+		add("capture.sigils."+sigil, config.Capture.Sigils[sigil])
+	}
+
+	templates := make([]string, 0, len(config.Capture.Templates))
+	for templateName := range config.Capture.Templates {
+		templates = append(templates, templateName)
+	}
+	sort.Strings(templates)
+	for _, templateName := range templates {
+		template := config.Capture.Templates[templateName]
+		addDefaults("capture.templates."+templateName+".defaults", template.Defaults)
+		addList("capture.templates."+templateName+".order", template.Order)
+		addList("capture.templates."+templateName+".skip", template.Skip)
+	}
+
+	add("properties.date", config.Properties.Date)
+	add("properties.priority", config.Properties.Priority)
+	add("properties.tags", config.Properties.Tags)
+	add("complete.status_property", config.Complete.StatusProperty)
+	add("complete.completed_property", config.Complete.CompletedProperty)
+
+	stale := []reference{}
+	for _, reference := range references {
+		if _, ok := db.Properties[reference.name]; !ok {
+			stale = append(stale, reference)
+		}
+	}
+	return stale
+}
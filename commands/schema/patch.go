@@ -0,0 +1,295 @@
+package schema
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/jomei/notionapi"
+	"gopkg.in/yaml.v2"
+
+	"github.com/crockeo/notion-cli/commands"
+	"github.com/crockeo/notion-cli/config"
+	"github.com/crockeo/notion-cli/errors"
+)
+
+var (
+	keyRegexp  = regexp.MustCompile(`^(\s*)("(?:[^"\\]|\\.)*"|'(?:[^']|'')*'|[^\s#'"-][^:#]*?|-[^\s:#][^:#]*?)\s*:(\s+(.*?))?\s*$`)
+	itemRegexp = regexp.MustCompile(`^(\s*-\s+)(.*?)\s*$`)
+)
+
+// valueKeys are the keys whose values name a property, by their parent key.
+// sigils name properties in all of their values.
+var valueKeys = map[string]map[string]bool{
+	"properties": {"date": true, "priority": true, "tags": true},
+	"complete":   {"status_property": true, "completed_property": true},
+}
+
+// patchConfig follows renames in the .notion-cli,
+// and returns the references which are still stale afterwards.
+// we edit the text line by line rather than re-marshalling the config,
+// which would throw away comments and the order of keys.
+func patchConfig(db *notionapi.Database, renames map[string]string) ([]reference, error) {
+	path, err := config.Path()
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	patched, count := patchText(string(contents), renames)
+	newConfig, err := checkPatch(contents, []byte(patched), renames)
+	if err != nil {
+		return nil, errors.Wrap(errors.KindConfig, err, "patching %s went wrong, so it was left alone", path)
+	}
+
+	if count > 0 {
+		if commands.DryRun {
+			fmt.Fprintf(os.Stderr, "would rename %d properties in %s\n", count, path)
+		} else {
+			if err := os.WriteFile(path, []byte(patched), info.Mode().Perm()); err != nil {
+				return nil, err
+			}
+			fmt.Fprintf(os.Stderr, "renamed %d properties in %s\n", count, path)
+		}
+	}
+
+	// names which aren't renamed in the database are still reported
+	return staleReferences(newConfig, db), nil
+}
+
+// checkPatch reads the patched config back in,
+// and makes sure that it's the original config with the renames applied,
+// i.e. that patchText didn't miss a reference or change anything else.
+func checkPatch(original []byte, patched []byte, renames map[string]string) (*config.Config, error) {
+	originalConfig := &config.Config{}
+	if err := yaml.Unmarshal(original, originalConfig); err != nil {
+		return nil, err
+	}
+	newConfig := &config.Config{}
+	if err := yaml.Unmarshal(patched, newConfig); err != nil {
+		return nil, errors.Wrap(errors.KindConfig, err, "the patched config isn't valid")
+	}
+	if expected := renameConfig(*originalConfig, renames); !reflect.DeepEqual(*newConfig, expected) {
+		return nil, errors.New(errors.KindConfig, "the patched config doesn't match the renames, rename the properties by hand")
+	}
+	return newConfig, nil
+}
+
+// renameConfig applies renames to the same references that patchText does.
+func renameConfig(c config.Config, renames map[string]string) config.Config {
+	rename := func(name string) string {
+		if newName, ok := renames[name]; ok {
+			return newName
+		}
+		return name
+	}
+	renameKeys := func(values map[string]string) map[string]string {
+		if values == nil {
+			return nil
+		}
+		renamed := make(map[string]string, len(values))
+		for propName, value := range values {
+			renamed[rename(propName)] = value
+		}
+		return renamed
+	}
+	renameList := func(propNames []string) []string {
+		if propNames == nil {
+			return nil
+		}
+		renamed := make([]string, len(propNames))
+		for i, propName := range propNames {
+			renamed[i] = rename(propName)
+		}
+		return renamed
+	}
+
+	c.Capture.Defaults = renameKeys(c.Capture.Defaults)
+	c.Capture.Order = renameList(c.Capture.Order)
+	if c.Capture.Sigils != nil {
+		sigils := make(map[string]string, len(c.Capture.Sigils))
+		for sigil, propName := range c.Capture.Sigils {
+			sigils[sigil] = rename(propName)
+		}
+		c.Capture.Sigils = sigils
+	}
+	if c.Capture.Templates != nil {
+		templates := make(map[string]config.CaptureTemplate, len(c.Capture.Templates))
+		for templateName, template := range c.Capture.Templates {
+			template.Defaults = renameKeys(template.Defaults)
+			template.Order = renameList(template.Order)
+			template.Skip = renameList(template.Skip)
+			templates[templateName] = template
+		}
+		c.Capture.Templates = templates
+	}
+	c.Properties.Date = rename(c.Properties.Date)
+	c.Properties.Priority = rename(c.Properties.Priority)
+	c.Properties.Tags = rename(c.Properties.Tags)
+	c.Complete.StatusProperty = rename(c.Complete.StatusProperty)
+	c.Complete.CompletedProperty = rename(c.Complete.CompletedProperty)
+	return c
+}
+
+// patchText renames the properties which the YAML in contents names
+// under defaults, order, skip, sigils, and valueKeys.
+// it tracks the keys each line is nested under by their indentation,
+// which is enough for the block style that configs are written in,
+// plus flow style lists under order and skip.
+func patchText(contents string, renames map[string]string) (string, int) {
+	type key struct {
+		indent int
+		name   string
+	}
+	parents := []key{}
+	parent := func() string {
+		if len(parents) == 0 {
+			return ""
+		}
+		return parents[len(parents)-1].name
+	}
+
+	count := 0
+	lines := strings.SplitAfter(contents, "\n")
+	for i, line := range lines {
+		text := strings.TrimRight(line, "\r\n")
+		ending := line[len(text):]
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(text) - len(strings.TrimLeft(text, " "))
+
+		if match := itemRegexp.FindStringSubmatch(text); match != nil {
+			for len(parents) > 0 && parents[len(parents)-1].indent > indent {
+				parents = parents[:len(parents)-1]
+			}
+			if parent() != "order" && parent() != "skip" {
+				continue
+			}
+			value, comment := splitComment(match[2])
+			if newName, ok := renames[unquote(value)]; ok {
+				lines[i] = match[1] + quote(newName) + comment + ending
+				count++
+			}
+			continue
+		}
+
+		match := keyRegexp.FindStringSubmatch(text)
+		if match == nil {
+			continue
+		}
+		for len(parents) > 0 && parents[len(parents)-1].indent >= indent {
+			parents = parents[:len(parents)-1]
+		}
+		name := unquote(match[2])
+		value, comment := splitComment(match[4])
+
+		switch {
+		case parent() == "defaults":
+			if newName, ok := renames[name]; ok {
+				separator := ":"
+				if match[3] != "" {
+					separator = ": "
+				}
+				lines[i] = match[1] + quote(newName) + separator + match[4] + ending
+				count++
+			}
+		case (name == "order" || name == "skip") && strings.HasPrefix(value, "["):
+			if newValue, renamed := patchFlowList(value, renames); renamed > 0 {
+				lines[i] = match[1] + match[2] + ": " + newValue + comment + ending
+				count += renamed
+			}
+		case parent() == "sigils" || valueKeys[parent()][name]:
+			if newName, ok := renames[unquote(value)]; ok {
+				lines[i] = match[1] + match[2] + ": " + quote(newName) + comment + ending
+				count++
+			}
+		}
+		parents = append(parents, key{indent: indent, name: name})
+	}
+	return strings.Join(lines, ""), count
+}
+
+// patchFlowList renames the properties in a list like [Name, "Due Date"],
+// and returns how many it renamed.
+func patchFlowList(value string, renames map[string]string) (string, int) {
+	var propNames []string
+	if err := yaml.Unmarshal([]byte(value), &propNames); err != nil {
+		return value, 0
+	}
+	count := 0
+	quoted := make([]string, len(propNames))
+	for i, propName := range propNames {
+		if newName, ok := renames[propName]; ok {
+			propName = newName
+			count++
+		}
+		// the commas and brackets which are fine in a block need quotes in a flow
+		if strings.ContainsAny(propName, ",[]{}") {
+			quoted[i] = fmt.Sprintf("%q", propName)
+		} else {
+			quoted[i] = quote(propName)
+		}
+	}
+	if count == 0 {
+		return value, 0
+	}
+	return "[" + strings.Join(quoted, ", ") + "]", count
+}
+
+// splitComment separates a scalar from a trailing comment,
+// leaving the whitespace before the comment with it.
+func splitComment(value string) (string, string) {
+	end := len(value)
+	switch {
+	case strings.HasPrefix(value, "\""):
+		for i := 1; i < len(value); i++ {
+			if value[i] == '\\' {
+				i++
+			} else if value[i] == '"' {
+				end = i + 1
+				break
+			}
+		}
+	case strings.HasPrefix(value, "'"):
+		if closing := strings.Index(value[1:], "'"); closing >= 0 {
+			end = closing + 2
+		}
+	default:
+		if index := strings.Index(value, " #"); index >= 0 {
+			end = len(strings.TrimRight(value[:index], " "))
+		}
+	}
+	return value[:end], value[end:]
+}
+
+func unquote(value string) string {
+	if len(value) < 2 || (value[0] != '"' && value[0] != '\'') {
+		return value
+	}
+	var unquoted string
+	if err := yaml.Unmarshal([]byte(value), &unquoted); err != nil {
+		return value
+	}
+	return unquoted
+}
+
+// quote writes name the way yaml.v2 would,
+// which only adds quotes when they're needed.
+func quote(name string) string {
+	bytes, err := yaml.Marshal(name)
+	if err != nil {
+		return fmt.Sprintf("%q", name)
+	}
+	return strings.TrimSuffix(string(bytes), "\n")
+}
@@ -0,0 +1,162 @@
+package schema
+
+import "testing"
+
+func TestPatchText(t *testing.T) {
+	renames := map[string]string{
+		"Due":      "Deadline",
+		"Old Tags": "Labels",
+		"Effort":   "Estimate, hours",
+	}
+
+	tests := []struct {
+		name     string
+		contents string
+		expected string
+		count    int
+	}{
+		{
+			name: "block style",
+			contents: `capture:
+  defaults:
+    Due: today
+  order:
+    - Due
+    - Status
+properties:
+  date: Due
+`,
+			expected: `capture:
+  defaults:
+    Deadline: today
+  order:
+    - Deadline
+    - Status
+properties:
+  date: Deadline
+`,
+			count: 3,
+		},
+		{
+			name: "quoted keys and values",
+			contents: `capture:
+  defaults:
+    "Old Tags": work
+    'Due': today
+  sigils:
+    "#": 'Old Tags'
+`,
+			expected: `capture:
+  defaults:
+    Labels: work
+    Deadline: today
+  sigils:
+    "#": Labels
+`,
+			count: 3,
+		},
+		{
+			name: "trailing comments",
+			contents: `properties:
+  date: Due # when it's due
+  tags: "Old Tags"  # what it's about
+capture:
+  order:
+    - Due # first
+`,
+			expected: `properties:
+  date: Deadline # when it's due
+  tags: Labels  # what it's about
+capture:
+  order:
+    - Deadline # first
+`,
+			count: 3,
+		},
+		{
+			name: "nested templates",
+			contents: `capture:
+  templates:
+    bug:
+      defaults:
+        Due: tomorrow
+      skip:
+        - Old Tags
+      body: |
+        Due: never
+complete:
+  completed_property: Due
+`,
+			expected: `capture:
+  templates:
+    bug:
+      defaults:
+        Deadline: tomorrow
+      skip:
+        - Labels
+      body: |
+        Due: never
+complete:
+  completed_property: Deadline
+`,
+			count: 3,
+		},
+		{
+			name: "flow lists",
+			contents: `capture:
+  order: [Due, "Old Tags", Status] # in this order
+  templates:
+    bug:
+      skip: [Effort]
+`,
+			expected: `capture:
+  order: [Deadline, Labels, Status] # in this order
+  templates:
+    bug:
+      skip: ["Estimate, hours"]
+`,
+			count: 3,
+		},
+		{
+			name: "other keys are left alone",
+			contents: `journal:
+  date_property: Due
+capture:
+  duplicate_threshold: 0.9
+  defaults:
+    Status: Due
+`,
+			expected: `journal:
+  date_property: Due
+capture:
+  duplicate_threshold: 0.9
+  defaults:
+    Status: Due
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			patched, count := patchText(test.contents, renames)
+			if patched != test.expected {
+				t.Fatalf("expected:\n%s\ngot:\n%s", test.expected, patched)
+			}
+			if count != test.count {
+				t.Fatalf("expected %d renames, got %d", test.count, count)
+			}
+			if _, err := checkPatch([]byte(test.contents), []byte(patched), renames); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestCheckPatchMissedRename(t *testing.T) {
+	contents := []byte("capture:\n  sigils: {\"#\": Due}\n")
+	renames := map[string]string{"Due": "Deadline"}
+	patched, _ := patchText(string(contents), renames)
+	if _, err := checkPatch(contents, []byte(patched), renames); err == nil {
+		t.Fatal("expected an error for the sigil which wasn't renamed")
+	}
+}
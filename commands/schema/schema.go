@@ -0,0 +1,59 @@
+package schema
+
+import (
+	"flag"
+	"os"
+
+	"github.com/jomei/notionapi"
+
+	"github.com/crockeo/notion-cli/commands"
+	"github.com/crockeo/notion-cli/config"
+	"github.com/crockeo/notion-cli/errors"
+)
+
+// every subcommand's flags live in the one FlagSet,
+// so that help lists them all
+var (
+	flags    = flag.NewFlagSet("schema", flag.ContinueOnError)
+	patch    = flags.Bool("patch", false, "Rename properties in the config to follow renames in the database.")
+	exitCode = flags.Bool("exit-code", false, "Exit with an error when anything has drifted, e.g. in CI.")
+	prune    = flags.Bool("prune", false, "Delete the properties and options which the schema file leaves out.")
+	yes      = flags.Bool("yes", false, "Apply the plan without asking for confirmation.")
+)
+
+var Command = &commands.Command{
+	Name:    "schema",
+	Summary: "Compare the database's schema against a snapshot, or update it from a schema file.",
+	Usage:   "diff [-patch] [-exit-code] <snapshot.json> | apply [-prune] [-yes] <schema.yaml>",
+	Examples: []string{
+		"dump > schema.json",
+		"schema diff schema.json",
+		"schema diff -patch schema.json",
+		"schema apply schema.yaml",
+		"--dry-run schema apply -prune schema.yaml",
+	},
+	Flags: flags,
+	Run:   Schema,
+}
+
+var subcommands = map[string]func(config *config.Config, client *notionapi.Client, args []string) error{
+	"diff":  Diff,
+	"apply": Apply,
+}
+
+func Schema(config *config.Config, client *notionapi.Client, args []string) error {
+	if len(args) == 0 {
+		return errors.New(errors.KindUsage, "expected a subcommand, like 'schema diff' or 'schema apply'")
+	}
+	run, ok := subcommands[args[0]]
+	if !ok {
+		return errors.New(errors.KindUsage, "unknown schema subcommand '%s'", args[0])
+	}
+
+	// flag parsing stopped at the subcommand,
+	// so we pick up the flags which come after it
+	if err := flags.Parse(args[1:]); err != nil {
+		return errors.New(errors.KindUsage, "%s (see '%s help schema')", err.Error(), os.Args[0])
+	}
+	return run(config, client, flags.Args())
+}
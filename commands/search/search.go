@@ -0,0 +1,251 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jomei/notionapi"
+	"github.com/manifoldco/promptui"
+
+	"github.com/crockeo/notion-cli/commands"
+	"github.com/crockeo/notion-cli/commands/appendbody"
+	"github.com/crockeo/notion-cli/config"
+	"github.com/crockeo/notion-cli/database"
+	"github.com/crockeo/notion-cli/errors"
+)
+
+var (
+	flags      = flag.NewFlagSet("search", flag.ContinueOnError)
+	objectType = flags.String("type", "", "Only return pages or databases.")
+	inDatabase = flags.Bool("database", false, "Only return pages in the configured database.")
+	limit      = flags.Int("limit", 20, "Maximum number of results.")
+	asJSON     = flags.Bool("json", false, "Print results as JSON.")
+	pick       = flags.Bool("pick", false, "Choose one result interactively and print its ID.")
+	then       = flags.String("then", "", "What to do with the -pick: open it, or append to its body. Implies -pick.")
+)
+
+var Command = &commands.Command{
+	Name:    "search",
+	Summary: "Search the pages and databases shared with notion-cli.",
+	Usage:   "[flags] <terms>",
+	Examples: []string{
+		"search quarterly planning",
+		"search -database -json plants",
+		"search -type database tasks",
+		"search -pick plants",
+		"search -then open quarterly planning",
+		"search -database -then append plants",
+	},
+	Flags: flags,
+	Run:   Search,
+}
+
+// result is what we show for each page or database that matches.
+type result struct {
+	ID         string    `json:"id"`
+	Object     string    `json:"object"`
+	Title      string    `json:"title"`
+	Parent     string    `json:"parent"`
+	LastEdited time.Time `json:"last_edited_time"`
+	URL        string    `json:"url"`
+}
+
+func Search(config *config.Config, client *notionapi.Client, args []string) error {
+	if *then != "" && *then != "open" && *then != "append" {
+		return errors.New(errors.KindUsage, "-then must be open or append, got '%s'", *then)
+	}
+	query := strings.Join(args, " ")
+
+	request := &notionapi.SearchRequest{
+		Query: query,
+		Sort: &notionapi.SortObject{
+			Timestamp: notionapi.TimestampLastEdited,
+			Direction: notionapi.SortOrderDESC,
+		},
+	}
+	switch {
+	case *inDatabase && *objectType == "database":
+		return errors.New(errors.KindUsage, "-database only returns pages, so it can't be used with -type database")
+	case *inDatabase:
+		request.Filter = map[string]string{"property": "object", "value": "page"}
+	case *objectType == "page" || *objectType == "database":
+		request.Filter = map[string]string{"property": "object", "value": *objectType}
+	case *objectType != "":
+		return errors.New(errors.KindUsage, "-type must be page or database, got '%s'", *objectType)
+	}
+
+	objects, err := search(config, client, request)
+	if err != nil {
+		return err
+	}
+
+	parents := newParentNames(client)
+	results := []result{}
+	for _, object := range objects {
+		switch object := object.(type) {
+		case *notionapi.Page:
+			results = append(results, result{
+				ID:         string(object.ID),
+				Object:     "page",
+				Title:      database.PageTitle(object),
+				Parent:     parents.get(object.Parent),
+				LastEdited: object.LastEditedTime,
+				URL:        object.URL,
+			})
+		case *notionapi.Database:
+			results = append(results, result{
+				ID:         string(object.ID),
+				Object:     "database",
+				Title:      database.DatabaseTitle(object),
+				Parent:     parents.get(object.Parent),
+				LastEdited: object.LastEditedTime,
+				URL:        object.URL,
+			})
+		}
+	}
+
+	switch {
+	case *pick || *then != "":
+		return pickResult(config, client, results)
+	case *asJSON:
+		bytes, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(bytes))
+		return nil
+	}
+
+	if len(results) == 0 {
+		fmt.Fprintf(os.Stderr, "nothing matches '%s'\n", query)
+		return nil
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, result := range results {
+		fmt.Fprintf(
+			writer,
+			"%s\t%s\t%s\t%s\n",
+			result.Title,
+			result.Parent,
+			result.LastEdited.Local().Format("2006-01-02 15:04"),
+			result.URL,
+		)
+	}
+	return writer.Flush()
+}
+
+// search follows the cursor until we have enough results.
+// the search endpoint can't filter by parent,
+// so -database is applied to each page of results as it comes in.
+func search(config *config.Config, client *notionapi.Client, request *notionapi.SearchRequest) ([]notionapi.Object, error) {
+	databaseID := database.NormalizeID(config.DatabaseID)
+
+	objects := []notionapi.Object{}
+	for len(objects) < *limit {
+		resp, err := client.Search.Do(context.Background(), request)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, object := range resp.Results {
+			if *inDatabase {
+				page, ok := object.(*notionapi.Page)
+				if !ok || database.NormalizeID(string(page.Parent.DatabaseID)) != databaseID {
+					continue
+				}
+			}
+			if len(objects) < *limit {
+				objects = append(objects, object)
+			}
+		}
+
+		if !resp.HasMore {
+			break
+		}
+		request.StartCursor = resp.NextCursor
+	}
+	return objects, nil
+}
+
+// pickResult lets someone choose a result, and then opens it,
+// appends to it, or prints its ID so that it can be passed along.
+// the prompt goes to stderr so that $(notion-cli search -pick ...) works.
+func pickResult(config *config.Config, client *notionapi.Client, results []result) error {
+	if len(results) == 0 {
+		return errors.New(errors.KindNotFound, "nothing matches")
+	}
+
+	items := make([]string, len(results))
+	for i, result := range results {
+		items[i] = fmt.Sprintf("%s (%s)", result.Title, result.Parent)
+	}
+	prompt := promptui.Select{
+		Label:  "Result",
+		Items:  items,
+		Stdout: os.Stderr,
+	}
+	i, _, err := prompt.Run()
+	if err != nil {
+		return err
+	}
+
+	picked := results[i]
+	switch *then {
+	case "open":
+		return commands.OpenURL(picked.URL)
+	case "append":
+		if picked.Object != "page" {
+			return errors.New(errors.KindUsage, "can only append to pages, and '%s' is a %s", picked.Title, picked.Object)
+		}
+		return appendbody.Append(config, client, []string{picked.ID})
+	}
+	fmt.Println(picked.ID)
+	return nil
+}
+
+// parentNames looks up the titles of parent pages and databases,
+// fetching each one at most once.
+type parentNames struct {
+	client *notionapi.Client
+	names  map[string]string
+}
+
+func newParentNames(client *notionapi.Client) *parentNames {
+	return &parentNames{client: client, names: map[string]string{}}
+}
+
+func (p *parentNames) get(parent notionapi.Parent) string {
+	var id string
+	switch {
+	case parent.DatabaseID != "":
+		id = string(parent.DatabaseID)
+	case parent.PageID != "":
+		id = string(parent.PageID)
+	default:
+		return "workspace"
+	}
+	if name, ok := p.names[id]; ok {
+		return name
+	}
+
+	// parents which aren't shared with us can't be fetched,
+	// so we fall back to their IDs
+	name := id
+	if parent.DatabaseID != "" {
+		if db, err := p.client.Database.Get(context.Background(), parent.DatabaseID); err == nil {
+			name = database.DatabaseTitle(db)
+		}
+	} else {
+		if page, err := p.client.Page.Get(context.Background(), parent.PageID); err == nil {
+			name = database.PageTitle(page)
+		}
+	}
+	p.names[id] = name
+	return name
+}
@@ -0,0 +1,212 @@
+package serve
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jomei/notionapi"
+
+	"github.com/crockeo/notion-cli/commands"
+	"github.com/crockeo/notion-cli/commands/capture"
+	"github.com/crockeo/notion-cli/database"
+	"github.com/crockeo/notion-cli/errors"
+	"github.com/crockeo/notion-cli/filter"
+	"github.com/crockeo/notion-cli/parse"
+)
+
+// page is how pages are sent back,
+// with properties formatted the same way capture reads them.
+type page struct {
+	ID         string            `json:"id"`
+	URL        string            `json:"url"`
+	Title      string            `json:"title"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+func newPage(notionPage *notionapi.Page, withProperties bool) page {
+	p := page{
+		ID:    string(notionPage.ID),
+		URL:   notionPage.URL,
+		Title: database.PageTitle(notionPage),
+	}
+	if withProperties {
+		p.Properties = map[string]string{}
+		for propName, property := range notionPage.Properties {
+			p.Properties[propName] = database.FormatProperty(property)
+		}
+	}
+	return p
+}
+
+// handleCapture creates a page from a capture.PropInfo,
+// e.g. {"properties": {"Name": "Water plants", "Due": "tomorrow"}, "body": "..."}
+func (s *server) handleCapture(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, errors.New(errors.KindUsage, "capture expects a POST"))
+		return
+	}
+
+	propInfo := &capture.PropInfo{}
+	if err := decodeBody(r, propInfo); err != nil {
+		writeError(w, err)
+		return
+	}
+	db, err := s.database()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	request, err := capture.BuildRequest(s.config, db, nil, propInfo)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	created, err := commands.CreatePage(s.client, request)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if created == nil {
+		writeJSON(w, http.StatusOK, map[string]bool{"dry_run": true})
+		return
+	}
+	writeJSON(w, http.StatusCreated, newPage(created, false))
+}
+
+// handleList returns the pages matching the where query parameter,
+// which is a filter expression like bulk-set's -where.
+// without one, it returns every open page.
+func (s *server) handleList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, errors.New(errors.KindUsage, "list expects a GET"))
+		return
+	}
+
+	limit := 0
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitParam); err != nil || limit < 0 {
+			writeError(w, errors.New(errors.KindUsage, "limit must be a positive number, got '%s'", limitParam))
+			return
+		}
+	}
+
+	var pages []notionapi.Page
+	if where := r.URL.Query().Get("where"); where != "" {
+		db, err := s.database()
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		f, err := filter.Parse(where, db)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if pages, err = f.Query(s.config, s.client); err != nil {
+			writeError(w, err)
+			return
+		}
+	} else {
+		request := &notionapi.DatabaseQueryRequest{PropertyFilter: database.OpenFilter(s.config)}
+		var err error
+		if pages, err = database.QueryAll(s.config, s.client, request); err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+
+	if limit > 0 && len(pages) > limit {
+		pages = pages[:limit]
+	}
+	results := make([]page, len(pages))
+	for i := range pages {
+		results[i] = newPage(&pages[i], true)
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+// handleComplete marks a page as done, the way complete would leave it,
+// e.g. {"id": "0123456789abcdef0123456789abcdef"}. the id can also be a URL.
+func (s *server) handleComplete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, errors.New(errors.KindUsage, "complete expects a POST"))
+		return
+	}
+
+	var body struct {
+		ID string `json:"id"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, err)
+		return
+	}
+	pageID, ok := database.ParsePageID(body.ID)
+	if !ok {
+		writeError(w, errors.New(errors.KindUsage, "expected the id of a page, got '%s'", body.ID))
+		return
+	}
+
+	properties, err := s.completeProperties()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	notionPage, err := s.client.Page.Get(r.Context(), pageID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	updated, err := commands.UpdatePage(s.client, notionPage, &notionapi.PageUpdateRequest{Properties: properties})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if updated == nil {
+		writeJSON(w, http.StatusOK, map[string]bool{"dry_run": true})
+		return
+	}
+	writeJSON(w, http.StatusOK, newPage(updated, false))
+}
+
+// completeProperties sets the status to done and stamps today's date,
+// using the same settings as complete.
+func (s *server) completeProperties() (notionapi.Properties, error) {
+	db, err := s.database()
+	if err != nil {
+		return nil, err
+	}
+	completeConfig := s.config.Complete
+	if _, ok := db.Properties[completeConfig.StatusProperty].(*notionapi.CheckboxPropertyConfig); !ok {
+		return nil, errors.New(errors.KindConfig, "config.Complete.StatusProperty is not a checkbox: %v", completeConfig.StatusProperty)
+	}
+	done, err := parse.ParseCheckbox(completeConfig.DoneStatus)
+	if err != nil {
+		return nil, errors.Wrap(errors.KindConfig, err, "config.Complete.DoneStatus is not a checkbox value")
+	}
+
+	properties := notionapi.Properties{completeConfig.StatusProperty: done}
+	if _, ok := db.Properties[completeConfig.CompletedProperty]; ok {
+		now := time.Now()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		properties[completeConfig.CompletedProperty] = &parse.DateProperty{
+			Date: parse.DateObject{Start: (*parse.TimelessDate)(&today)},
+		}
+	}
+	return properties, nil
+}
+
+func decodeBody(r *http.Request, value interface{}) error {
+	contents, err := io.ReadAll(io.LimitReader(r.Body, 1024*1024))
+	if err != nil {
+		return errors.Wrap(errors.KindNetwork, err, "failed to read the request")
+	}
+	if err := json.Unmarshal(contents, value); err != nil {
+		return errors.Wrap(errors.KindUsage, err, "the request is not valid JSON")
+	}
+	return nil
+}
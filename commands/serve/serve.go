@@ -0,0 +1,190 @@
+package serve
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jomei/notionapi"
+
+	"github.com/crockeo/notion-cli/commands"
+	"github.com/crockeo/notion-cli/config"
+	"github.com/crockeo/notion-cli/database"
+	"github.com/crockeo/notion-cli/errors"
+)
+
+// secretHeader is the header every request has to send the secret in.
+const secretHeader = "X-Notion-CLI-Secret"
+
+var (
+	flags       = flag.NewFlagSet("serve", flag.ContinueOnError)
+	listen      = flags.String("listen", "127.0.0.1:8787", "Address to listen on.")
+	secretFile  = flags.String("secret-file", "", "File holding the shared secret that requests send in the "+secretHeader+" header. Defaults to $NOTION_CLI_SECRET.")
+	allowOrigin = flags.String("allow-origin", "", "Origin which browsers may call from, e.g. for a bookmarklet. By default only non-browser clients can call.")
+	schemaTTL   = flags.Duration("schema-ttl", 10*time.Minute, "How long the database schema is reused before it's fetched again.")
+)
+
+var Command = &commands.Command{
+	Name:    "serve",
+	Summary: "Serve capture, list, and complete as a local JSON API.",
+	Usage:   "[flags]",
+	Examples: []string{
+		"serve -secret-file ~/.config/notion-cli/secret",
+		"serve -listen 127.0.0.1:9000 -schema-ttl 1h",
+	},
+	Flags: flags,
+	Run:   Serve,
+}
+
+// server answers requests against the configured database.
+// the schema is kept in memory between requests,
+// and comes from the same cache as shell completion.
+type server struct {
+	config *config.Config
+	client *notionapi.Client
+	secret string
+
+	lock     sync.Mutex
+	schema   *notionapi.Database
+	loadedAt time.Time
+}
+
+func Serve(config *config.Config, client *notionapi.Client, args []string) error {
+	// the secret isn't taken as a flag,
+	// since anyone on the machine can read flags from ps
+	sharedSecret := os.Getenv("NOTION_CLI_SECRET")
+	if *secretFile != "" {
+		contents, err := os.ReadFile(*secretFile)
+		if err != nil {
+			return errors.Wrap(errors.KindUsage, err, "could not read -secret-file")
+		}
+		sharedSecret = strings.TrimSpace(string(contents))
+	}
+	if sharedSecret == "" {
+		return errors.New(errors.KindUsage, "-secret-file or $NOTION_CLI_SECRET is required, so that other local programs can't write to Notion")
+	}
+
+	s := &server{config: config, client: client, secret: sharedSecret}
+	if _, err := s.database(); err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/capture", s.handleCapture)
+	mux.HandleFunc("/list", s.handleList)
+	mux.HandleFunc("/complete", s.handleComplete)
+	httpServer := &http.Server{
+		Addr:              *listen,
+		Handler:           s.middleware(mux),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("listening on http://%s", *listen)
+	if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return errors.Wrap(errors.KindNetwork, err, "failed to listen on %s", *listen)
+	}
+	return nil
+}
+
+// database returns the schema, refreshing it once it's older than -schema-ttl.
+func (s *server) database() (*notionapi.Database, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.schema != nil && time.Since(s.loadedAt) < *schemaTTL {
+		return s.schema, nil
+	}
+
+	schema, err := database.GetCached(s.config, s.client, *schemaTTL)
+	if err != nil {
+		return nil, err
+	}
+	s.schema = schema
+	s.loadedAt = time.Now()
+	return schema, nil
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// middleware logs every request, answers CORS preflights
+// from -allow-origin when it's set, and checks the secret.
+func (s *server) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		defer func() {
+			log.Printf("%s %s %d %s", r.Method, r.URL.Path, recorder.status, time.Since(start).Round(time.Millisecond))
+		}()
+
+		if *allowOrigin != "" {
+			w.Header().Set("Access-Control-Allow-Origin", *allowOrigin)
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, "+secretHeader)
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+			if r.Method == http.MethodOptions {
+				recorder.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get(secretHeader)), []byte(s.secret)) != 1 {
+			writeError(recorder, errors.New(errors.KindAuth, "missing or wrong %s header", secretHeader))
+			return
+		}
+		next.ServeHTTP(recorder, r)
+	})
+}
+
+var statusCodes = map[errors.Kind]int{
+	errors.KindUsage:      http.StatusBadRequest,
+	errors.KindValidation: http.StatusBadRequest,
+	errors.KindAuth:       http.StatusUnauthorized,
+	errors.KindNotFound:   http.StatusNotFound,
+	errors.KindRateLimit:  http.StatusTooManyRequests,
+	errors.KindNetwork:    http.StatusBadGateway,
+}
+
+// writeError responds with the same envelope as --output json.
+func writeError(w http.ResponseWriter, err error) {
+	status, ok := statusCodes[errors.KindOf(err)]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(commands.MarshalError(err), '\n'))
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(bytes, '\n'))
+}
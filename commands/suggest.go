@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/jomei/notionapi"
+
+	"github.com/crockeo/notion-cli/config"
+)
+
+// CompleteProperties suggests Prop=value arguments:
+// property names until there's an '=',
+// and then option names for selects, multi-selects and checkboxes.
+func CompleteProperties(config *config.Config, database *notionapi.Database, current string) []string {
+	propName, value, hasValue := Cut(current, "=")
+	if !hasValue {
+		candidates := []string{}
+		for propName, propConfig := range database.Properties {
+			if _, ok := propConfig.(*notionapi.FormulaPropertyConfig); ok {
+				continue
+			}
+			candidates = append(candidates, propName+"=")
+		}
+		sort.Strings(candidates)
+		return candidates
+	}
+
+	propConfig, ok := database.Properties[propName]
+	if !ok {
+		return []string{}
+	}
+
+	// multi-selects take comma separated options,
+	// so we only complete the last one
+	prefix := propName + "="
+	if _, ok := propConfig.(*notionapi.MultiSelectPropertyConfig); ok {
+		if i := strings.LastIndex(value, ","); i >= 0 {
+			prefix += value[:i+1]
+		}
+	}
+
+	candidates := []string{}
+	for _, option := range OptionNames(propConfig) {
+		candidates = append(candidates, prefix+option)
+	}
+	return candidates
+}
+
+// CompleteSigils suggests option names after a quick capture sigil,
+// e.g. "#ad" becomes "#admin".
+func CompleteSigils(config *config.Config, database *notionapi.Database, current string) []string {
+	candidates := []string{}
+	for sigil, propName := range config.Capture.Sigils {
+		if !strings.HasPrefix(current, sigil) {
+			continue
+		}
+		propConfig, ok := database.Properties[propName]
+		if !ok {
+			continue
+		}
+		for _, option := range OptionNames(propConfig) {
+			if strings.Contains(option, " ") {
+				option = "\"" + option + "\""
+			}
+			candidates = append(candidates, sigil+option)
+		}
+	}
+	return candidates
+}
+
+// OptionNames lists the values a property can take,
+// for the property types that have a fixed set of them.
+func OptionNames(propConfig notionapi.PropertyConfig) []string {
+	var options []notionapi.Option
+	switch propConfig := propConfig.(type) {
+	case *notionapi.SelectPropertyConfig:
+		options = propConfig.Select.Options
+	case *notionapi.MultiSelectPropertyConfig:
+		options = propConfig.MultiSelect.Options
+	case *notionapi.CheckboxPropertyConfig:
+		return []string{"true", "false"}
+	}
+
+	names := make([]string, len(options))
+	for i, option := range options {
+		names[i] = option.Name
+	}
+	return names
+}
+
+// Cut splits s around the first sep, like strings.Cut,
+// which we can't use until the module moves past Go 1.17.
+func Cut(s, sep string) (string, string, bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
@@ -0,0 +1,126 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/jomei/notionapi"
+
+	"github.com/crockeo/notion-cli/database"
+	"github.com/crockeo/notion-cli/parse"
+)
+
+func (t *tui) render() {
+	width, height := t.screen.width, t.screen.height
+	if height < 3 {
+		return
+	}
+	t.loadBody()
+
+	listWidth := width * 2 / 5
+	detailWidth := width - listWidth - 3
+	rows := height - 2
+
+	// keep the selection in view
+	if t.selected < t.offset {
+		t.offset = t.selected
+	}
+	if t.selected >= t.offset+rows {
+		t.offset = t.selected - rows + 1
+	}
+
+	detail := t.detail(detailWidth)
+	lines := []string{inverse(fit(fmt.Sprintf(" notion-cli  %d open", len(t.pages)), width))}
+	for row := 0; row < rows; row++ {
+		left := fit("", listWidth)
+		if i := t.offset + row; i < len(t.pages) {
+			left = fit(t.listItem(&t.pages[i]), listWidth)
+			if i == t.selected {
+				left = inverse(left)
+			}
+		}
+		right := ""
+		if row < len(detail) {
+			right = fit(detail[row], detailWidth)
+		}
+		lines = append(lines, left+" │ "+right)
+	}
+	lines = append(lines, t.bottomLine(width))
+
+	t.screen.draw(lines)
+}
+
+func (t *tui) bottomLine(width int) string {
+	if t.input != nil {
+		return fit(fmt.Sprintf("%s: %s█", t.input.Label, t.input.Text), width)
+	}
+	if t.status != "" {
+		return fit(t.status, width)
+	}
+	return fit(help, width)
+}
+
+func (t *tui) listItem(page *notionapi.Page) string {
+	check := "[ ]"
+	if !t.isOpen(page) {
+		check = "[x]"
+	}
+	date := "      "
+	if start := t.date(page); !start.IsZero() {
+		date = start.Format("Jan 02")
+	}
+	return fmt.Sprintf(" %s %s  %s", check, date, database.PageTitle(page))
+}
+
+// detail renders the properties and body of the current page.
+func (t *tui) detail(width int) []string {
+	page := t.current()
+	if page == nil {
+		return []string{"nothing open"}
+	}
+
+	lines := wrap(database.PageTitle(page), width)
+	lines = append(lines, "")
+
+	propNames := []string{}
+	for propName, property := range page.Properties {
+		if _, ok := property.(*notionapi.TitleProperty); !ok {
+			propNames = append(propNames, propName)
+		}
+	}
+	sort.Strings(propNames)
+	for _, propName := range propNames {
+		value := formatProperty(page.Properties[propName])
+		if value != "" {
+			lines = append(lines, fmt.Sprintf("%s: %s", propName, value))
+		}
+	}
+	lines = append(lines, "")
+
+	body, ok := t.bodies[page.ID]
+	switch {
+	case !ok:
+		lines = append(lines, "loading...")
+	case len(body.Lines) == 1 && body.Lines[0] == "":
+		lines = append(lines, "(no body)")
+	default:
+		for _, line := range body.Lines {
+			lines = append(lines, wrap(line, width)...)
+		}
+	}
+	return lines
+}
+
+// formatProperty is database.FormatProperty,
+// except that dates are shown the way people read them.
+func formatProperty(property notionapi.Property) string {
+	date, ok := property.(*notionapi.DateProperty)
+	if !ok || date.Date.Start == nil {
+		return database.FormatProperty(property)
+	}
+	start, timeless := parse.FromNotionDate(date.Date.Start)
+	if timeless {
+		return start.Format("Mon Jan 2, 2006")
+	}
+	return start.Local().Format("Mon Jan 2, 2006 15:04")
+}
@@ -0,0 +1,139 @@
+package tui
+
+import (
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/chzyer/readline"
+
+	"github.com/crockeo/notion-cli/errors"
+)
+
+// screen owns the terminal while the TUI is running:
+// it's in raw mode, on the alternate screen, with the cursor hidden.
+type screen struct {
+	fd     int
+	state  *readline.State
+	width  int
+	height int
+}
+
+func newScreen() (*screen, error) {
+	fd := int(os.Stdin.Fd())
+	if !readline.IsTerminal(fd) || !readline.IsTerminal(int(os.Stdout.Fd())) {
+		return nil, errors.New(errors.KindUsage, "tui must be run in a terminal")
+	}
+	screen := &screen{fd: fd}
+	if err := screen.resume(); err != nil {
+		return nil, err
+	}
+	return screen, nil
+}
+
+// suspend gives the terminal back,
+// e.g. so that capture can prompt as it normally would.
+func (s *screen) suspend() {
+	os.Stdout.WriteString("\x1b[?25h\x1b[?1049l")
+	if s.state != nil {
+		readline.Restore(s.fd, s.state)
+		s.state = nil
+	}
+}
+
+func (s *screen) resume() error {
+	state, err := readline.MakeRaw(s.fd)
+	if err != nil {
+		return errors.Wrap(errors.KindUnknown, err, "could not put the terminal in raw mode")
+	}
+	s.state = state
+	os.Stdout.WriteString("\x1b[?1049h\x1b[?25l")
+	s.resized()
+	return nil
+}
+
+// resized refreshes the size of the terminal,
+// and reports whether it's changed since we last looked.
+func (s *screen) resized() bool {
+	width, height, err := readline.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		width, height = 80, 24
+	}
+	changed := width != s.width || height != s.height
+	s.width, s.height = width, height
+	return changed
+}
+
+// draw replaces what's on screen with lines,
+// each of which must already fit in the width of the terminal.
+func (s *screen) draw(lines []string) {
+	var builder strings.Builder
+	builder.WriteString("\x1b[H")
+	for i, line := range lines {
+		if i > 0 {
+			builder.WriteString("\r\n")
+		}
+		builder.WriteString(line)
+		builder.WriteString("\x1b[K")
+	}
+	builder.WriteString("\x1b[J")
+	os.Stdout.WriteString(builder.String())
+}
+
+// readKeys sends each chunk read from stdin to keys,
+// and then waits on resume before reading again
+// so that whatever handles the key can take over stdin.
+func readKeys(keys chan<- string, resume <-chan bool) {
+	buf := make([]byte, 32)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			close(keys)
+			return
+		}
+		keys <- string(buf[:n])
+		<-resume
+	}
+}
+
+// fit truncates or pads text to exactly width columns.
+func fit(text string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	text = strings.NewReplacer("\t", "  ", "\r", "", "\n", " ").Replace(text)
+	if utf8.RuneCountInString(text) > width {
+		runes := []rune(text)
+		return string(runes[:width-1]) + "…"
+	}
+	return text + strings.Repeat(" ", width-utf8.RuneCountInString(text))
+}
+
+// wrap breaks text into lines no wider than width,
+// preserving the lines which are already there.
+func wrap(text string, width int) []string {
+	lines := []string{}
+	for _, line := range strings.Split(text, "\n") {
+		// keep the indentation of nested lists and code
+		current := line[:len(line)-len(strings.TrimLeft(line, " "))]
+		empty := true
+		for _, word := range strings.Fields(line) {
+			switch {
+			case empty:
+				current += word
+				empty = false
+			case utf8.RuneCountInString(current)+1+utf8.RuneCountInString(word) <= width:
+				current += " " + word
+			default:
+				lines = append(lines, current)
+				current = word
+			}
+		}
+		lines = append(lines, current)
+	}
+	return lines
+}
+
+func inverse(text string) string {
+	return "\x1b[7m" + text + "\x1b[0m"
+}
@@ -0,0 +1,553 @@
+package tui
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jomei/notionapi"
+
+	"github.com/crockeo/notion-cli/commands"
+	"github.com/crockeo/notion-cli/commands/capture"
+	"github.com/crockeo/notion-cli/config"
+	"github.com/crockeo/notion-cli/database"
+	"github.com/crockeo/notion-cli/errors"
+	"github.com/crockeo/notion-cli/markdown"
+	"github.com/crockeo/notion-cli/parse"
+)
+
+var (
+	flags   = flag.NewFlagSet("tui", flag.ContinueOnError)
+	refresh = flags.Duration("refresh", 30*time.Second, "How often to check for pages which changed elsewhere.")
+)
+
+var Command = &commands.Command{
+	Name:     "tui",
+	Summary:  "Browse and triage open items in a full-screen terminal UI.",
+	Usage:    "[flags]",
+	Examples: []string{"tui", "tui -refresh 1m"},
+	Flags:    flags,
+	Run:      TUI,
+}
+
+const help = "j/k move  x done  p priority  s set  d reschedule  o open  c capture  r reload  q quit"
+
+// body is the rendered Markdown of a page,
+// as of the last time the page was edited.
+type body struct {
+	LastEdited time.Time
+	Lines      []string
+}
+
+type tui struct {
+	config *config.Config
+	client *notionapi.Client
+	db     *notionapi.Database
+	screen *screen
+
+	pages    []notionapi.Page
+	selected int
+	offset   int
+	bodies   map[notionapi.ObjectID]*body
+	loading  map[notionapi.ObjectID]bool
+
+	// input is the prompt on the bottom line, if one is open
+	input    *input
+	status   string
+	since    time.Time
+	updates  chan func()
+	fetching bool
+}
+
+type input struct {
+	Label  string
+	Text   string
+	Submit func(text string)
+}
+
+func TUI(config *config.Config, client *notionapi.Client, args []string) error {
+	if commands.DryRun {
+		return errors.New(errors.KindUsage, "tui does not support --dry-run")
+	}
+
+	db, err := database.GetSync(config, client)
+	if err != nil {
+		return err
+	}
+
+	t := &tui{
+		config:  config,
+		client:  client,
+		db:      db,
+		bodies:  map[notionapi.ObjectID]*body{},
+		loading: map[notionapi.ObjectID]bool{},
+		updates: make(chan func()),
+	}
+	if err := t.reload(); err != nil {
+		return err
+	}
+
+	t.screen, err = newScreen()
+	if err != nil {
+		return err
+	}
+	defer t.screen.suspend()
+	return t.run()
+}
+
+func (t *tui) run() error {
+	keys := make(chan string)
+	resume := make(chan bool)
+	go readKeys(keys, resume)
+
+	refreshTicker := time.NewTicker(*refresh)
+	defer refreshTicker.Stop()
+	resizeTicker := time.NewTicker(time.Second)
+	defer resizeTicker.Stop()
+
+	t.render()
+	for {
+		select {
+		case key, ok := <-keys:
+			if !ok || t.handleKey(key) {
+				return nil
+			}
+			resume <- true
+		case update := <-t.updates:
+			update()
+		case <-refreshTicker.C:
+			t.refresh()
+		case <-resizeTicker.C:
+			if !t.screen.resized() {
+				continue
+			}
+		}
+		t.render()
+	}
+}
+
+// async runs work off of the main loop so that the screen stays responsive,
+// and then applies whatever it returns on the main loop.
+func (t *tui) async(status string, work func() (func(), error)) {
+	t.status = status
+	go func() {
+		apply, err := work()
+		t.updates <- func() {
+			if err != nil {
+				t.status = err.Error()
+				return
+			}
+			t.status = ""
+			if apply != nil {
+				apply()
+			}
+		}
+	}()
+}
+
+// handleKey reacts to a key press,
+// and reports whether we should quit.
+func (t *tui) handleKey(key string) bool {
+	if t.input != nil {
+		t.handleInput(key)
+		return false
+	}
+
+	switch key {
+	case "q", "\x03":
+		return true
+	case "j", "\x1b[B":
+		t.move(1)
+	case "k", "\x1b[A":
+		t.move(-1)
+	case "g", "\x1b[H":
+		t.move(-len(t.pages))
+	case "G", "\x1b[F":
+		t.move(len(t.pages))
+	case "r":
+		t.async("reloading...", func() (func(), error) {
+			pages, since, err := t.query()
+			if err != nil {
+				return nil, err
+			}
+			return func() { t.setPages(pages, since) }, nil
+		})
+	case "x":
+		t.toggle()
+	case "p":
+		t.cyclePriority()
+	case "s":
+		t.prompt("set (Property=value)", "", t.set)
+	case "d":
+		if t.config.Properties.Date == "" {
+			t.status = "config.Properties.Date must be set to reschedule"
+		} else {
+			t.prompt("reschedule", "", t.reschedule)
+		}
+	case "o":
+		if page := t.current(); page != nil {
+			if err := commands.OpenURL(page.URL); err != nil {
+				t.status = err.Error()
+			}
+		}
+	case "c":
+		t.capture()
+	}
+	return false
+}
+
+func (t *tui) handleInput(key string) {
+	switch key {
+	case "\r", "\n":
+		input := t.input
+		t.input = nil
+		if strings.TrimSpace(input.Text) != "" {
+			input.Submit(strings.TrimSpace(input.Text))
+		}
+	case "\x1b", "\x03":
+		t.input = nil
+	case "\x7f", "\b":
+		if runes := []rune(t.input.Text); len(runes) > 0 {
+			t.input.Text = string(runes[:len(runes)-1])
+		}
+	case "\x15":
+		t.input.Text = ""
+	default:
+		// ignore escape sequences and other control characters
+		if !strings.HasPrefix(key, "\x1b") && key[0] >= ' ' {
+			t.input.Text += key
+		}
+	}
+}
+
+func (t *tui) prompt(label string, text string, submit func(text string)) {
+	if t.current() == nil {
+		return
+	}
+	t.input = &input{Label: label, Text: text, Submit: submit}
+}
+
+func (t *tui) current() *notionapi.Page {
+	if t.selected < 0 || t.selected >= len(t.pages) {
+		return nil
+	}
+	return &t.pages[t.selected]
+}
+
+func (t *tui) move(delta int) {
+	t.selected += delta
+	if t.selected >= len(t.pages) {
+		t.selected = len(t.pages) - 1
+	}
+	if t.selected < 0 {
+		t.selected = 0
+	}
+}
+
+// update sets properties on the current page,
+// and swaps in the page that the API gives back.
+func (t *tui) update(properties notionapi.Properties) {
+	page := t.current()
+	if page == nil {
+		return
+	}
+	previous := *page
+	t.async("saving...", func() (func(), error) {
+		updated, err := commands.UpdatePage(t.client, &previous, &notionapi.PageUpdateRequest{Properties: properties})
+		if err != nil {
+			return nil, err
+		}
+		return func() { t.upsert(*updated, true) }, nil
+	})
+}
+
+func (t *tui) toggle() {
+	page := t.current()
+	if page == nil {
+		return
+	}
+	statusProperty := t.config.Complete.StatusProperty
+	if statusProperty == "" {
+		t.status = "config.Complete.StatusProperty must be set to mark items done"
+		return
+	}
+	checkbox, _ := page.Properties[statusProperty].(*notionapi.CheckboxProperty)
+	t.update(notionapi.Properties{
+		statusProperty: &notionapi.CheckboxProperty{Checkbox: checkbox == nil || !checkbox.Checkbox},
+	})
+}
+
+// cyclePriority moves the current page to the next priority,
+// in the order of the select's options.
+func (t *tui) cyclePriority() {
+	page := t.current()
+	if page == nil {
+		return
+	}
+	priorityProperty := t.config.Properties.Priority
+	propConfig, ok := t.db.Properties[priorityProperty].(*notionapi.SelectPropertyConfig)
+	if !ok || len(propConfig.Select.Options) == 0 {
+		t.status = "config.Properties.Priority must be set to a select to change priority"
+		return
+	}
+
+	options := propConfig.Select.Options
+	next := options[0]
+	if priority, ok := page.Properties[priorityProperty].(*notionapi.SelectProperty); ok {
+		for i, option := range options {
+			if option.Name == priority.Select.Name {
+				next = options[(i+1)%len(options)]
+			}
+		}
+	}
+	t.update(notionapi.Properties{
+		priorityProperty: &notionapi.SelectProperty{Select: notionapi.Option{Name: next.Name}},
+	})
+}
+
+func (t *tui) set(text string) {
+	propName, propValue, ok := commands.Cut(text, "=")
+	if !ok {
+		t.status = "expected Property=value"
+		return
+	}
+	propName = strings.TrimSpace(propName)
+	propConfig, ok := t.db.Properties[propName]
+	if !ok {
+		t.status = fmt.Sprintf("property '%s' does not exist in the database", propName)
+		return
+	}
+	property, err := parse.Property(propName, propConfig, strings.TrimSpace(propValue))
+	if err != nil {
+		t.status = fmt.Sprintf("invalid value for '%s': %s", propName, err)
+		return
+	}
+	t.update(notionapi.Properties{propName: property})
+}
+
+func (t *tui) reschedule(text string) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	date, err := parse.ParseDate(text, today)
+	if err != nil {
+		t.status = fmt.Sprintf("could not understand '%s' as a date", text)
+		return
+	}
+	t.update(notionapi.Properties{t.config.Properties.Date: date})
+}
+
+// capture hands the terminal over to the regular interactive capture,
+// and picks up the new page when it's done.
+func (t *tui) capture() {
+	t.screen.suspend()
+	err := capture.Capture(t.config, t.client, []string{})
+	if resumeErr := t.screen.resume(); resumeErr != nil {
+		t.status = resumeErr.Error()
+		return
+	}
+
+	switch {
+	case err == nil:
+		t.refresh()
+	case errors.KindOf(err) == errors.KindUserAbort:
+		t.status = ""
+	default:
+		t.status = err.Error()
+	}
+}
+
+// reload fetches every open page from scratch.
+func (t *tui) reload() error {
+	pages, since, err := t.query()
+	if err != nil {
+		return err
+	}
+	t.setPages(pages, since)
+	return nil
+}
+
+// query fetches every open page,
+// along with the time from which they're up to date.
+func (t *tui) query() ([]notionapi.Page, time.Time, error) {
+	started := time.Now()
+	pages, err := database.QueryAll(t.config, t.client, &notionapi.DatabaseQueryRequest{
+		PropertyFilter: database.OpenFilter(t.config),
+	})
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return pages, started, nil
+}
+
+func (t *tui) setPages(pages []notionapi.Page, since time.Time) {
+	selectedID := t.selectedID()
+	t.pages = pages
+	t.since = since
+	t.sortPages()
+	t.selectID(selectedID)
+}
+
+// refresh only fetches the pages which were edited since we last looked,
+// newest first, so it's cheap enough to do in the background.
+func (t *tui) refresh() {
+	if t.fetching {
+		return
+	}
+	t.fetching = true
+
+	// last_edited_time is only precise to the minute
+	since := t.since.Add(-time.Minute)
+	started := time.Now()
+	go func() {
+		changed := []notionapi.Page{}
+		request := &notionapi.DatabaseQueryRequest{
+			Sorts: []notionapi.SortObject{
+				{Timestamp: notionapi.TimestampLastEdited, Direction: notionapi.SortOrderDESC},
+			},
+		}
+		var err error
+		for {
+			var resp *notionapi.DatabaseQueryResponse
+			resp, err = t.client.Database.Query(context.Background(), notionapi.DatabaseID(t.config.DatabaseID), request)
+			if err != nil {
+				break
+			}
+			done := !resp.HasMore
+			for _, page := range resp.Results {
+				if page.LastEditedTime.Before(since) {
+					done = true
+					break
+				}
+				changed = append(changed, page)
+			}
+			if done {
+				break
+			}
+			request.StartCursor = resp.NextCursor
+		}
+
+		t.updates <- func() {
+			t.fetching = false
+			if err != nil {
+				t.status = err.Error()
+				return
+			}
+			t.since = started
+			for _, page := range changed {
+				t.upsert(page, false)
+			}
+		}
+	}()
+}
+
+// upsert puts a changed page into the list,
+// or takes it out if it's no longer open.
+// pages we edited ourselves are kept,
+// so that marking something done by accident is easy to take back.
+func (t *tui) upsert(page notionapi.Page, keep bool) {
+	selectedID := t.selectedID()
+	pages := []notionapi.Page{}
+	found := false
+	for _, existing := range t.pages {
+		if existing.ID == page.ID {
+			found = true
+		} else {
+			pages = append(pages, existing)
+		}
+	}
+	if !page.Archived && (t.isOpen(&page) || keep && found) {
+		pages = append(pages, page)
+	}
+	t.pages = pages
+	t.sortPages()
+	t.selectID(selectedID)
+}
+
+func (t *tui) isOpen(page *notionapi.Page) bool {
+	statusProperty := t.config.Complete.StatusProperty
+	if statusProperty == "" {
+		return true
+	}
+	checkbox, ok := page.Properties[statusProperty].(*notionapi.CheckboxProperty)
+	if !ok {
+		return true
+	}
+	done, err := parse.ParseCheckbox(t.config.Complete.DoneStatus)
+	if err != nil {
+		return !checkbox.Checkbox
+	}
+	return checkbox.Checkbox != done.Checkbox
+}
+
+// sortPages orders pages by their date, undated pages last,
+// and then by title so that the order is stable between refreshes.
+func (t *tui) sortPages() {
+	dates := map[notionapi.ObjectID]time.Time{}
+	for i := range t.pages {
+		dates[t.pages[i].ID] = t.date(&t.pages[i])
+	}
+	sort.SliceStable(t.pages, func(i, j int) bool {
+		a, b := dates[t.pages[i].ID], dates[t.pages[j].ID]
+		if !a.Equal(b) {
+			return !a.IsZero() && (b.IsZero() || a.Before(b))
+		}
+		return database.PageTitle(&t.pages[i]) < database.PageTitle(&t.pages[j])
+	})
+}
+
+func (t *tui) date(page *notionapi.Page) time.Time {
+	date, ok := page.Properties[t.config.Properties.Date].(*notionapi.DateProperty)
+	if !ok || date.Date.Start == nil {
+		return time.Time{}
+	}
+	start, _ := parse.FromNotionDate(date.Date.Start)
+	return start
+}
+
+func (t *tui) selectedID() notionapi.ObjectID {
+	if page := t.current(); page != nil {
+		return page.ID
+	}
+	return ""
+}
+
+func (t *tui) selectID(id notionapi.ObjectID) {
+	for i := range t.pages {
+		if t.pages[i].ID == id {
+			t.selected = i
+			return
+		}
+	}
+	t.move(0)
+}
+
+// loadBody fetches the body of the current page,
+// unless we already have it as of its last edit.
+func (t *tui) loadBody() {
+	page := t.current()
+	if page == nil || t.loading[page.ID] {
+		return
+	}
+	if body, ok := t.bodies[page.ID]; ok && body.LastEdited.Equal(page.LastEditedTime) {
+		return
+	}
+
+	t.loading[page.ID] = true
+	pageID := page.ID
+	lastEdited := page.LastEditedTime
+	go func() {
+		blocks, err := database.GetBlocks(t.client, notionapi.PageID(pageID))
+		t.updates <- func() {
+			delete(t.loading, pageID)
+			if err != nil {
+				t.status = err.Error()
+				return
+			}
+			contents := strings.TrimRight(string(markdown.FromBlocks(blocks)), "\n")
+			t.bodies[pageID] = &body{LastEdited: lastEdited, Lines: strings.Split(contents, "\n")}
+		}
+	}()
+}
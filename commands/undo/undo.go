@@ -0,0 +1,180 @@
+package undo
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jomei/notionapi"
+
+	"github.com/crockeo/notion-cli/commands"
+	"github.com/crockeo/notion-cli/config"
+	"github.com/crockeo/notion-cli/database"
+	"github.com/crockeo/notion-cli/errors"
+)
+
+var (
+	undoFlags = flag.NewFlagSet("undo", flag.ContinueOnError)
+	last      = undoFlags.Int("last", 1, "Undo the changes made by the last N commands.")
+	since     = undoFlags.Duration("since", 0, "Undo every change made within this long, e.g. 1h. Overrides -last.")
+	list      = undoFlags.Bool("list", false, "List the changes which would be undone instead of undoing them.")
+
+	uncompleteFlags = flag.NewFlagSet("uncomplete", flag.ContinueOnError)
+	uncompleteLast  = uncompleteFlags.Int("last", 1, "Undo the last N runs of complete.")
+	uncompleteSince = uncompleteFlags.Duration("since", 0, "Undo every run of complete within this long, e.g. 1h. Overrides -last.")
+	uncompleteList  = uncompleteFlags.Bool("list", false, "List the changes which would be undone instead of undoing them.")
+)
+
+var Command = &commands.Command{
+	Name:     "undo",
+	Summary:  "Revert changes recorded in the undo journal.",
+	Usage:    "[-last N | -since duration]",
+	Examples: []string{"undo", "undo -last 3", "undo -since 1h", "undo -list -since 24h"},
+	Flags:    undoFlags,
+	Run:      Undo,
+}
+
+var UncompleteCommand = &commands.Command{
+	Name:     "uncomplete",
+	Summary:  "Remove the completion dates written by complete.",
+	Usage:    "[-last N | -since duration]",
+	Examples: []string{"uncomplete", "uncomplete -since 2h"},
+	Flags:    uncompleteFlags,
+	Run:      Uncomplete,
+}
+
+func Undo(config *config.Config, client *notionapi.Client, args []string) error {
+	return undo(client, "", *last, *since, *list)
+}
+
+func Uncomplete(config *config.Config, client *notionapi.Client, args []string) error {
+	return undo(client, "complete", *uncompleteLast, *uncompleteSince, *uncompleteList)
+}
+
+// undo reverts the most recent entries in the journal,
+// optionally only those which were written by command.
+func undo(client *notionapi.Client, command string, lastRuns int, since time.Duration, list bool) error {
+	if lastRuns < 1 && since <= 0 {
+		return errors.New(errors.KindUsage, "-last must be at least 1")
+	}
+
+	journal, err := commands.ReadJournal()
+	if err != nil {
+		return err
+	}
+
+	selected := selectEntries(journal, command, lastRuns, since)
+	if len(selected) == 0 {
+		fmt.Println("nothing to undo")
+		return nil
+	}
+
+	if list {
+		for i := len(journal) - 1; i >= 0; i-- {
+			if selected[i] {
+				fmt.Println(describe(journal[i]))
+			}
+		}
+		return nil
+	}
+
+	// newest first, so that a page changed twice
+	// ends up with the value from before the first change
+	reverted := map[int]bool{}
+	var revertErr error
+	for i := len(journal) - 1; i >= 0; i-- {
+		if !selected[i] {
+			continue
+		}
+		if revertErr = commands.Revert(client, journal[i]); revertErr != nil {
+			revertErr = errors.Wrap(errors.KindOf(revertErr), revertErr, "failed to undo %s", describe(journal[i]))
+			break
+		}
+		if !commands.DryRun {
+			fmt.Println("undid " + describe(journal[i]))
+		}
+		reverted[i] = true
+	}
+	if commands.DryRun {
+		return revertErr
+	}
+
+	// whatever we managed to revert comes out of the journal,
+	// even when something fails part way through
+	remaining := []commands.JournalEntry{}
+	for i, entry := range journal {
+		if !reverted[i] {
+			remaining = append(remaining, entry)
+		}
+	}
+	if err := commands.WriteJournal(remaining); err != nil {
+		return err
+	}
+	if revertErr != nil {
+		return revertErr
+	}
+	fmt.Fprintf(os.Stderr, "undid %d changes\n", len(reverted))
+	return nil
+}
+
+// selectEntries picks the indexes of the entries to undo:
+// everything since a point in time, or everything from the last few runs.
+func selectEntries(journal []commands.JournalEntry, command string, lastRuns int, since time.Duration) map[int]bool {
+	selected := map[int]bool{}
+	cutoff := time.Now().Add(-since)
+	runs := map[string]bool{}
+	for i := len(journal) - 1; i >= 0; i-- {
+		entry := journal[i]
+		if command != "" && entry.Command != command {
+			continue
+		}
+
+		if since > 0 {
+			if entry.Time.Before(cutoff) {
+				break
+			}
+		} else if !runs[entry.Run] {
+			if len(runs) == lastRuns {
+				break
+			}
+			runs[entry.Run] = true
+		}
+		selected[i] = true
+	}
+	return selected
+}
+
+func describe(entry commands.JournalEntry) string {
+	return fmt.Sprintf(
+		"%s %s %s: %s -> %s",
+		entry.Time.Local().Format("2006-01-02 15:04"),
+		entry.PageID,
+		entry.Property,
+		formatValue(entry.Property, entry.New),
+		formatValue(entry.Property, entry.Previous),
+	)
+}
+
+// formatValue renders a journaled value the same way parse.Property reads it.
+func formatValue(propName string, value json.RawMessage) string {
+	if propName == commands.ArchivedProperty {
+		return "archived=" + string(value)
+	}
+
+	properties := notionapi.Properties{}
+	wrapped, err := json.Marshal(map[string]json.RawMessage{propName: value})
+	if err == nil {
+		err = json.Unmarshal(wrapped, &properties)
+	}
+	if err != nil {
+		return string(value)
+	}
+
+	formatted := database.FormatProperty(properties[propName])
+	if formatted == "" {
+		return "(empty)"
+	}
+	return formatted
+}
@@ -1,25 +1,47 @@
 package config
 
 import (
-	"errors"
 	"os"
 	"path/filepath"
 
 	"github.com/jomei/notionapi"
 	"gopkg.in/yaml.v2"
+
+	"github.com/crockeo/notion-cli/errors"
 )
 
 type Config struct {
 	DatabaseID string          `yaml:"database"`
 	Token      notionapi.Token `yaml:"token"`
 
-	Capture  CaptureConfig  `yaml:"capture"`
-	Complete CompleteConfig `yaml:"complete"`
+	Capture    CaptureConfig    `yaml:"capture"`
+	Complete   CompleteConfig   `yaml:"complete"`
+	Properties PropertiesConfig `yaml:"properties"`
+	Journal    JournalConfig    `yaml:"journal"`
+	Import     ImportConfig     `yaml:"import"`
 }
 
 type CaptureConfig struct {
 	Defaults map[string]string `yaml:"defaults"`
 	Order    []string          `yaml:"order"`
+	Sigils   map[string]string `yaml:"sigils"`
+
+	// DuplicateThreshold is how similar (from 0 to 1) a title has to be
+	// to an open item's title for capture to consider it a duplicate
+	DuplicateThreshold float64 `yaml:"duplicate_threshold"`
+
+	Templates map[string]CaptureTemplate `yaml:"templates"`
+}
+
+// CaptureTemplate describes a shape of task we capture often.
+// its Defaults and Order are layered on top of the CaptureConfig,
+// Body is a Markdown skeleton which can reference {{title}} and {{date}},
+// and properties in Skip are never prompted for.
+type CaptureTemplate struct {
+	Defaults map[string]string `yaml:"defaults"`
+	Order    []string          `yaml:"order"`
+	Body     string            `yaml:"body"`
+	Skip     []string          `yaml:"skip"`
 }
 
 type CompleteConfig struct {
@@ -28,10 +50,77 @@ type CompleteConfig struct {
 	CompletedProperty string `yaml:"completed_property"`
 }
 
+// PropertiesConfig names the properties which play a particular role
+// in the database, for the commands which display tasks.
+type PropertiesConfig struct {
+	Date     string `yaml:"date"`
+	Priority string `yaml:"priority"`
+	Tags     string `yaml:"tags"`
+}
+
+// JournalConfig describes the database that journal keeps a page per day in.
+// pages are titled with TitleFormat (a Go time format),
+// and DateProperty is set to the day when it's provided.
+type JournalConfig struct {
+	DatabaseID   string `yaml:"database"`
+	TitleFormat  string `yaml:"title_format"`
+	DateProperty string `yaml:"date_property"`
+}
+
+// ImportConfig maps the fields of each format that import reads
+// onto the properties of the database.
+type ImportConfig struct {
+	TodoTxt     ImportMapping `yaml:"todotxt"`
+	Taskwarrior ImportMapping `yaml:"taskwarrior"`
+	Org         ImportMapping `yaml:"org"`
+}
+
+// ImportMapping names the property that each field is written to.
+// Priority, Tags, Due, Done and Completed fall back to the properties
+// in PropertiesConfig and CompleteConfig, the rest are left out unless set.
+// DoneValue is what Done is set to for finished tasks,
+// which defaults to CompleteConfig's DoneStatus.
+// Priorities translates priorities like A or H into option names,
+// and Fields maps anything else, e.g. todo.txt's key:value pairs,
+// Taskwarrior's UDAs and org's property drawers.
+type ImportMapping struct {
+	Priority   string            `yaml:"priority"`
+	Priorities map[string]string `yaml:"priorities"`
+	Contexts   string            `yaml:"contexts"`
+	Projects   string            `yaml:"projects"`
+	Tags       string            `yaml:"tags"`
+	Due        string            `yaml:"due"`
+	Done       string            `yaml:"done"`
+	DoneValue  string            `yaml:"done_value"`
+	Completed  string            `yaml:"completed"`
+	Fields     map[string]string `yaml:"fields"`
+}
+
 func Load() (*Config, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	contents, err := os.ReadFile(path)
+	if err != nil || len(contents) == 0 {
+		return nil, errors.New(errors.KindConfig, "could not find a .notion-cli")
+	}
+
+	config := &Config{}
+	err = yaml.Unmarshal(contents, config)
+	if err != nil {
+		return nil, errors.Wrap(errors.KindConfig, err, "failed to parse .notion-cli")
+	}
+
+	return config, nil
+}
+
+// Path finds the .notion-cli that Load reads,
+// for the commands which need to change it.
+func Path() (string, error) {
 	home, ok := os.LookupEnv("HOME")
 	if !ok {
-		return nil, errors.New("program not provided HOME directory")
+		return "", errors.New(errors.KindConfig, "program not provided HOME directory")
 	}
 
 	files := []string{
@@ -51,24 +140,25 @@ func Load() (*Config, error) {
 		}
 	}
 
-	var contents []byte
-	var err error
 	for _, path := range paths {
-		contents, err = os.ReadFile(path)
-		if err == nil {
-			break
+		if info, err := os.Stat(path); err == nil && info.Size() > 0 {
+			return path, nil
 		}
 	}
+	return "", errors.New(errors.KindConfig, "could not find a .notion-cli")
+}
 
-	if len(contents) == 0 {
-		return nil, errors.New("could not find a .notion-cli")
-	}
-
-	config := &Config{}
-	err = yaml.Unmarshal(contents, config)
+// StatePath is where notion-cli keeps a file between runs.
+// unlike the schema cache, these files (e.g. logs of what we've changed)
+// shouldn't be thrown away, so they live in the user's config directory.
+func StatePath(name string) (string, error) {
+	configDir, err := os.UserConfigDir()
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-
-	return config, nil
+	dir := filepath.Join(configDir, "notion-cli")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
 }
@@ -0,0 +1,69 @@
+package database
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jomei/notionapi"
+
+	"github.com/crockeo/notion-cli/config"
+)
+
+// GetCached returns the database schema from the local cache
+// when the cache is younger than maxAge (or when maxAge is negative),
+// and otherwise fetches the schema and refreshes the cache.
+// it's meant for things like shell completion,
+// where a slightly stale schema beats waiting on the API.
+func GetCached(config *config.Config, client *notionapi.Client, maxAge time.Duration) (*notionapi.Database, error) {
+	path, err := cachePath(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if info, err := os.Stat(path); err == nil && (maxAge < 0 || time.Since(info.ModTime()) < maxAge) {
+		contents, err := os.ReadFile(path)
+		if err == nil {
+			database := &notionapi.Database{}
+			if err := json.Unmarshal(contents, database); err == nil {
+				return database, nil
+			}
+		}
+	}
+
+	database, err := GetSync(config, client)
+	if err != nil {
+		return nil, err
+	}
+
+	// failing to write the cache shouldn't fail the command,
+	// we'll just fetch the schema again next time
+	_ = WriteCache(config, database)
+	return database, nil
+}
+
+// WriteCache stores a freshly fetched schema for GetCached.
+func WriteCache(config *config.Config, database *notionapi.Database) error {
+	path, err := cachePath(config)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	contents, err := json.Marshal(database)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, contents, 0600)
+}
+
+func cachePath(config *config.Config) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "notion-cli", "schema-"+config.DatabaseID+".json"), nil
+}
@@ -2,10 +2,17 @@ package database
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/jomei/notionapi"
 
 	"github.com/crockeo/notion-cli/config"
+	"github.com/crockeo/notion-cli/errors"
+	"github.com/crockeo/notion-cli/parse"
 )
 
 func Get(config *config.Config, client *notionapi.Client) (chan *notionapi.Database, chan error) {
@@ -40,3 +47,219 @@ func Join(databaseChan chan *notionapi.Database, errChan chan error) (*notionapi
 		return nil, err
 	}
 }
+
+// TitleProperty finds the name of the database's title property.
+// every Notion database has exactly one.
+func TitleProperty(database *notionapi.Database) (string, bool) {
+	for propName, propConfig := range database.Properties {
+		if _, ok := propConfig.(*notionapi.TitlePropertyConfig); ok {
+			return propName, true
+		}
+	}
+	return "", false
+}
+
+// PageTitle renders the plain text title of a page
+// which was returned from the API.
+func PageTitle(page *notionapi.Page) string {
+	for _, property := range page.Properties {
+		if titleProperty, ok := property.(*notionapi.TitleProperty); ok {
+			return plainText(titleProperty.Title)
+		}
+	}
+	return ""
+}
+
+// DatabaseTitle renders the plain text title of a database.
+func DatabaseTitle(database *notionapi.Database) string {
+	return plainText(database.Title)
+}
+
+// NormalizeID strips the dashes from an object ID,
+// since the API uses IDs both with and without them.
+func NormalizeID(id string) string {
+	return strings.Replace(id, "-", "", -1)
+}
+
+// QueryAll runs a query against the configured database,
+// following the cursor until every page has been returned.
+func QueryAll(config *config.Config, client *notionapi.Client, request *notionapi.DatabaseQueryRequest) ([]notionapi.Page, error) {
+	if request == nil {
+		request = &notionapi.DatabaseQueryRequest{}
+	}
+
+	pages := []notionapi.Page{}
+	hasMore := true
+	for hasMore {
+		resp, err := client.Database.Query(context.Background(), notionapi.DatabaseID(config.DatabaseID), request)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, resp.Results...)
+
+		request.StartCursor = resp.NextCursor
+		hasMore = resp.HasMore
+	}
+	return pages, nil
+}
+
+// OpenFilter matches pages which haven't been completed,
+// according to config.Complete.StatusProperty.
+// it's nil when no status property is configured,
+// in which case every page is considered open.
+func OpenFilter(config *config.Config) *notionapi.PropertyFilter {
+	if config.Complete.StatusProperty == "" {
+		return nil
+	}
+
+	// the API client omits false booleans,
+	// so we have to phrase the condition in terms of true
+	condition := &notionapi.CheckboxFilterCondition{}
+	if done, err := parse.ParseCheckbox(config.Complete.DoneStatus); err == nil && !done.Checkbox {
+		condition.Equals = true
+	} else {
+		condition.DoesNotEqual = true
+	}
+	return &notionapi.PropertyFilter{
+		Property: config.Complete.StatusProperty,
+		Checkbox: condition,
+	}
+}
+
+// FormatProperty renders the value of a page property as a string,
+// in the same format that parse.Property reads it back in.
+func FormatProperty(property notionapi.Property) string {
+	switch property := property.(type) {
+	case *notionapi.TitleProperty:
+		return plainText(property.Title)
+	case *notionapi.RichTextProperty:
+		return plainText(property.RichText)
+	case *notionapi.NumberProperty:
+		return strconv.FormatFloat(property.Number, 'f', -1, 64)
+	case *notionapi.SelectProperty:
+		return property.Select.Name
+	case *notionapi.MultiSelectProperty:
+		names := make([]string, len(property.MultiSelect))
+		for i, option := range property.MultiSelect {
+			names[i] = option.Name
+		}
+		return strings.Join(names, ",")
+	case *notionapi.DateProperty:
+		if property.Date.Start == nil {
+			return ""
+		}
+		start, timeless := parse.FromNotionDate(property.Date.Start)
+		if timeless {
+			return start.Format("2006-01-02")
+		}
+		return start.Format(time.RFC3339)
+	case *notionapi.CheckboxProperty:
+		return strconv.FormatBool(property.Checkbox)
+	case *notionapi.URLProperty:
+		return property.URL
+	case *notionapi.EmailProperty:
+		return property.Email
+	case *notionapi.PhoneNumberProperty:
+		return property.PhoneNumber
+	case *notionapi.RelationProperty:
+		ids := make([]string, len(property.Relation))
+		for i, relation := range property.Relation {
+			ids[i] = string(relation.ID)
+		}
+		return strings.Join(ids, ",")
+	}
+	return ""
+}
+
+func plainText(richTexts []notionapi.RichText) string {
+	text := ""
+	for _, richText := range richTexts {
+		if richText.PlainText != "" {
+			text += richText.PlainText
+		} else {
+			text += richText.Text.Content
+		}
+	}
+	return text
+}
+
+// GetBlocks fetches every top-level block in a page's body.
+func GetBlocks(client *notionapi.Client, pageID notionapi.PageID) ([]notionapi.Block, error) {
+	blocks := []notionapi.Block{}
+	pagination := &notionapi.Pagination{}
+	for {
+		resp, err := client.Block.GetChildren(context.Background(), notionapi.BlockID(pageID), pagination)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, resp.Results...)
+
+		if !resp.HasMore {
+			return blocks, nil
+		}
+		pagination.StartCursor = notionapi.Cursor(resp.NextCursor)
+	}
+}
+
+// ParsePageID finds the page ID in a page ID or Notion URL,
+// which ends in the page's ID with the dashes removed.
+func ParsePageID(candidate string) (notionapi.PageID, bool) {
+	candidate = strings.TrimSpace(candidate)
+	if parse.IsObjectID(candidate) {
+		return notionapi.PageID(candidate), true
+	}
+
+	if !strings.HasPrefix(candidate, "http") {
+		return "", false
+	}
+	if i := strings.IndexAny(candidate, "?#"); i >= 0 {
+		candidate = candidate[:i]
+	}
+	if len(candidate) < 32 || !parse.IsObjectID(candidate[len(candidate)-32:]) {
+		return "", false
+	}
+	return notionapi.PageID(candidate[len(candidate)-32:]), true
+}
+
+// PropertyID returns the ID of a property in the database's schema,
+// which unlike its name doesn't change when the property is renamed.
+// the API client types every config's ID differently,
+// so we read it back out of the JSON.
+func PropertyID(propConfig notionapi.PropertyConfig) string {
+	bytes, err := json.Marshal(propConfig)
+	if err != nil {
+		return ""
+	}
+	var withID struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(bytes, &withID); err != nil {
+		return ""
+	}
+	return withID.ID
+}
+
+// NumberFormats fetches the format of every number property, by name.
+// the API sends it as "number": {"format": ...}, which the API client's
+// NumberPropertyConfig doesn't read, so we fetch the schema ourselves.
+func NumberFormats(config *config.Config, client *notionapi.Client) (map[string]string, error) {
+	var raw struct {
+		Properties map[string]struct {
+			Type   string `json:"type"`
+			Number struct {
+				Format string `json:"format"`
+			} `json:"number"`
+		} `json:"properties"`
+	}
+	if err := request(client, http.MethodGet, "databases/"+config.DatabaseID, nil, &raw); err != nil {
+		return nil, errors.Wrap(errors.KindOf(err), err, "failed to fetch the number formats")
+	}
+
+	formats := map[string]string{}
+	for propName, property := range raw.Properties {
+		if property.Type == "number" {
+			formats[propName] = property.Number.Format
+		}
+	}
+	return formats, nil
+}
@@ -0,0 +1,105 @@
+package database
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/jomei/notionapi"
+
+	"github.com/crockeo/notion-cli/config"
+	"github.com/crockeo/notion-cli/errors"
+)
+
+const (
+	apiURL = "https://api.notion.com/v1/"
+	// the same version that the API client sends
+	notionVersion = "2021-08-16"
+)
+
+// request sends a request to the API directly,
+// for the parts of the API which the API client can't express.
+// responses other than 200 come back as a *notionapi.Error,
+// like they do from the API client.
+func request(client *notionapi.Client, method string, path string, body interface{}, response interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequest(method, apiURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+client.Token.String())
+	req.Header.Set("Notion-Version", notionVersion)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.Wrap(errors.KindNetwork, err, "failed to reach the API")
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		apiErr := &notionapi.Error{}
+		if err := json.NewDecoder(res.Body).Decode(apiErr); err != nil {
+			return errors.Wrap(errors.KindNetwork, err, "the API responded with %s", res.Status)
+		}
+		return apiErr
+	}
+	if err := json.NewDecoder(res.Body).Decode(response); err != nil {
+		return errors.Wrap(errors.KindNetwork, err, "failed to read the API's response")
+	}
+	return nil
+}
+
+// dayQuery is a database query for the pages whose date property
+// falls on a day. notionapi.Date always marshals as a datetime with an offset,
+// which the API compares as an instant rather than as a day,
+// so the day is sent on its own.
+type dayQuery struct {
+	Filter struct {
+		Property string `json:"property"`
+		Date     struct {
+			Equals string `json:"equals"`
+		} `json:"date"`
+	} `json:"filter"`
+	StartCursor notionapi.Cursor `json:"start_cursor,omitempty"`
+}
+
+func newDayQuery(property string, day time.Time) *dayQuery {
+	query := &dayQuery{}
+	query.Filter.Property = property
+	query.Filter.Date.Equals = day.Format("2006-01-02")
+	return query
+}
+
+// QueryDay returns every page in the configured database
+// whose date property falls on day, in day's location.
+func QueryDay(config *config.Config, client *notionapi.Client, property string, day time.Time) ([]notionapi.Page, error) {
+	query := newDayQuery(property, day)
+	path := "databases/" + config.DatabaseID + "/query"
+
+	pages := []notionapi.Page{}
+	for {
+		resp := &notionapi.DatabaseQueryResponse{}
+		if err := request(client, http.MethodPost, path, query, resp); err != nil {
+			return nil, err
+		}
+		pages = append(pages, resp.Results...)
+
+		if !resp.HasMore {
+			return pages, nil
+		}
+		query.StartCursor = resp.NextCursor
+	}
+}
@@ -0,0 +1,57 @@
+package database
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestDayQuery(t *testing.T) {
+	east := time.FixedZone("east", 14*60*60)
+	west := time.FixedZone("west", -12*60*60)
+
+	tests := []struct {
+		name     string
+		day      time.Time
+		cursor   string
+		expected string
+	}{
+		{
+			name:     "midnight",
+			day:      time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+			expected: `{"filter":{"property":"Date","date":{"equals":"2026-10-19"}}}`,
+		},
+		{
+			name:     "ahead of UTC",
+			day:      time.Date(2026, 10, 19, 0, 0, 0, 0, east),
+			expected: `{"filter":{"property":"Date","date":{"equals":"2026-10-19"}}}`,
+		},
+		{
+			name:     "behind UTC",
+			day:      time.Date(2026, 10, 19, 23, 59, 0, 0, west),
+			expected: `{"filter":{"property":"Date","date":{"equals":"2026-10-19"}}}`,
+		},
+		{
+			name:     "next page",
+			day:      time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+			cursor:   "abc",
+			expected: `{"filter":{"property":"Date","date":{"equals":"2026-10-19"}},"start_cursor":"abc"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			query := newDayQuery("Date", test.day)
+			query.StartCursor = notionapi.Cursor(test.cursor)
+			encoded, err := json.Marshal(query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(encoded) != test.expected {
+				t.Fatalf("expected %s, got %s", test.expected, encoded)
+			}
+		})
+	}
+}
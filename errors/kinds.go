@@ -0,0 +1,138 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+
+	"github.com/jomei/notionapi"
+	"github.com/manifoldco/promptui"
+)
+
+// Kind groups errors by what the user (or their script)
+// can do about them. every Kind has its own exit code.
+type Kind string
+
+const (
+	KindUnknown    Kind = "unknown"
+	KindUsage      Kind = "usage"
+	KindConfig     Kind = "config"
+	KindAuth       Kind = "auth"
+	KindNotFound   Kind = "not_found"
+	KindValidation Kind = "validation"
+	KindRateLimit  Kind = "rate_limit"
+	KindNetwork    Kind = "network"
+	KindUserAbort  Kind = "user_abort"
+)
+
+var exitCodes = map[Kind]int{
+	KindUnknown:    1,
+	KindUsage:      2,
+	KindConfig:     3,
+	KindAuth:       4,
+	KindNotFound:   5,
+	KindValidation: 6,
+	KindRateLimit:  7,
+	KindNetwork:    8,
+	KindUserAbort:  130,
+}
+
+func (k Kind) ExitCode() int {
+	if code, ok := exitCodes[k]; ok {
+		return code
+	}
+	return exitCodes[KindUnknown]
+}
+
+// Error is an error with a Kind attached,
+// optionally wrapping the error which caused it.
+type Error struct {
+	Kind    Kind
+	Message string
+	Cause   error
+}
+
+func New(kind Kind, format string, args ...interface{}) *Error {
+	return &Error{
+		Kind:    kind,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+func Wrap(kind Kind, cause error, format string, args ...interface{}) *Error {
+	return &Error{
+		Kind:    kind,
+		Message: fmt.Sprintf(format, args...),
+		Cause:   cause,
+	}
+}
+
+func (e *Error) Error() string {
+	if e.Cause == nil {
+		return e.Message
+	}
+	if e.Message == "" {
+		return e.Cause.Error()
+	}
+	return fmt.Sprintf("%s: %s", e.Message, e.Cause.Error())
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// KindOf classifies any error, including the ones
+// which come back from the Notion API, the network, and prompts.
+func KindOf(err error) Kind {
+	var kindErr *Error
+	if errors.As(err, &kindErr) {
+		return kindErr.Kind
+	}
+
+	var failedParse *ErrFailedParse
+	var invalidPropertyConfig *ErrInvalidPropertyConfig
+	if errors.As(err, &failedParse) || errors.As(err, &invalidPropertyConfig) {
+		return KindValidation
+	}
+
+	var apiErr *notionapi.Error
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.Status == 401 || apiErr.Status == 403:
+			return KindAuth
+		case apiErr.Status == 404:
+			return KindNotFound
+		case apiErr.Status == 429:
+			return KindRateLimit
+		case apiErr.Status == 400 || apiErr.Status == 409:
+			return KindValidation
+		case apiErr.Status >= 500:
+			return KindNetwork
+		}
+		return KindUnknown
+	}
+
+	var netErr net.Error
+	var urlErr *url.Error
+	if errors.As(err, &netErr) || errors.As(err, &urlErr) {
+		return KindNetwork
+	}
+
+	if errors.Is(err, promptui.ErrInterrupt) || errors.Is(err, promptui.ErrEOF) || errors.Is(err, promptui.ErrAbort) {
+		return KindUserAbort
+	}
+
+	return KindUnknown
+}
+
+// Cause finds the innermost error that err wraps.
+func Cause(err error) error {
+	for {
+		next := errors.Unwrap(err)
+		if next == nil {
+			return err
+		}
+		err = next
+	}
+}
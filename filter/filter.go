@@ -0,0 +1,399 @@
+// Package filter parses the filter expressions
+// that commands which act on many pages at once select them with, e.g.
+//
+//	Tags~Q3 and Priority!=Low and created<60 days ago
+//
+// each clause is a property, an operator, and a value,
+// and clauses are joined with "and".
+// the operators are = != < <= > >= and ~ (contains) !~ (doesn't contain).
+// a missing value matches empty properties, e.g. "Due=".
+// created and edited match when the page was created and last edited.
+package filter
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jomei/notionapi"
+
+	"github.com/crockeo/notion-cli/config"
+	"github.com/crockeo/notion-cli/database"
+	"github.com/crockeo/notion-cli/errors"
+	"github.com/crockeo/notion-cli/parse"
+)
+
+// Filter is a parsed filter expression.
+// clauses which the API can filter on are sent with the query,
+// and the rest are matched against the pages which come back.
+type Filter struct {
+	api   []notionapi.PropertyFilter
+	local []func(page *notionapi.Page) bool
+}
+
+type clause struct {
+	PropName string
+	Op       string
+	Value    string
+}
+
+var (
+	andPattern = regexp.MustCompile(`(?i)\s+and\s+`)
+
+	// longer operators come first, so that "<=" isn't read as "<"
+	operators = []string{"!=", "!~", "<=", ">=", "=", "~", "<", ">"}
+)
+
+// Parse parses expr against the database's properties.
+func Parse(expr string, db *notionapi.Database) (*Filter, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, errors.New(errors.KindUsage, "filter expression is empty")
+	}
+
+	filter := &Filter{}
+	for _, part := range andPattern.Split(strings.TrimSpace(expr), -1) {
+		clause, err := parseClause(part)
+		if err != nil {
+			return nil, err
+		}
+		if err := filter.add(clause, db); err != nil {
+			return nil, err
+		}
+	}
+	return filter, nil
+}
+
+func parseClause(part string) (clause, error) {
+	i := strings.IndexAny(part, "!=<>~")
+	if i <= 0 {
+		return clause{}, errors.New(errors.KindUsage, "expected Property<operator>value in filter, got '%s'", part)
+	}
+	for _, op := range operators {
+		if strings.HasPrefix(part[i:], op) {
+			return clause{
+				PropName: strings.TrimSpace(part[:i]),
+				Op:       op,
+				Value:    unquote(strings.TrimSpace(part[i+len(op):])),
+			}, nil
+		}
+	}
+	return clause{}, errors.New(errors.KindUsage, "unknown operator in filter clause '%s'", part)
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+func (f *Filter) add(c clause, db *notionapi.Database) error {
+	switch c.PropName {
+	case "created":
+		return f.addTimestamp(c, func(page *notionapi.Page) time.Time { return page.CreatedTime })
+	case "edited":
+		return f.addTimestamp(c, func(page *notionapi.Page) time.Time { return page.LastEditedTime })
+	}
+
+	propConfig, ok := db.Properties[c.PropName]
+	if !ok {
+		return errors.New(errors.KindValidation, "filter property '%s' does not exist in the database", c.PropName)
+	}
+
+	invalid := func() error {
+		return errors.New(errors.KindValidation, "operator '%s' can't be used with %s property '%s'", c.Op, propConfig.GetType(), c.PropName)
+	}
+	property := notionapi.PropertyFilter{Property: c.PropName}
+
+	switch propConfig := propConfig.(type) {
+	case *notionapi.TitlePropertyConfig, *notionapi.RichTextPropertyConfig:
+		condition := &notionapi.TextFilterCondition{}
+		switch {
+		case c.Value == "" && (c.Op == "=" || c.Op == "!="):
+			condition.IsEmpty = c.Op == "="
+			condition.IsNotEmpty = c.Op == "!="
+		case c.Op == "=":
+			condition.Equals = c.Value
+		case c.Op == "!=":
+			condition.DoesNotEqual = c.Value
+		case c.Op == "~":
+			condition.Contains = c.Value
+		case c.Op == "!~":
+			condition.DoesNotContain = c.Value
+		default:
+			return invalid()
+		}
+		property.Text = condition
+
+	case *notionapi.SelectPropertyConfig:
+		condition := &notionapi.SelectFilterCondition{}
+		name, err := optionName(c, propConfig.Select.Options)
+		if err != nil {
+			return err
+		}
+		switch {
+		case name == "" && (c.Op == "=" || c.Op == "!="):
+			condition.IsEmpty = c.Op == "="
+			condition.IsNotEmpty = c.Op == "!="
+		case c.Op == "=":
+			condition.Equals = name
+		case c.Op == "!=":
+			condition.DoesNotEqual = name
+		default:
+			return invalid()
+		}
+		property.Select = condition
+
+	case *notionapi.MultiSelectPropertyConfig:
+		condition := &notionapi.MultiSelectFilterCondition{}
+		name, err := optionName(c, propConfig.MultiSelect.Options)
+		if err != nil {
+			return err
+		}
+		switch {
+		case name == "" && (c.Op == "=" || c.Op == "!="):
+			condition.IsEmpty = c.Op == "="
+			condition.IsNotEmpty = c.Op == "!="
+		case c.Op == "=" || c.Op == "~":
+			condition.Contains = name
+		case c.Op == "!=" || c.Op == "!~":
+			condition.DoesNotContain = name
+		default:
+			return invalid()
+		}
+		property.MultiSelect = condition
+
+	case *notionapi.DatePropertyConfig:
+		condition := &notionapi.DateFilterCondition{}
+		if c.Value == "" {
+			switch c.Op {
+			case "=":
+				condition.IsEmpty = true
+			case "!=":
+				condition.IsNotEmpty = true
+			default:
+				return invalid()
+			}
+		} else {
+			date, err := parseDate(c)
+			if err != nil {
+				return err
+			}
+			notionDate := notionapi.Date(date)
+			switch c.Op {
+			case "=":
+				condition.Equals = &notionDate
+			case "<":
+				condition.Before = &notionDate
+			case "<=":
+				condition.OnOrBefore = &notionDate
+			case ">":
+				condition.After = &notionDate
+			case ">=":
+				condition.OnOrAfter = &notionDate
+			default:
+				return invalid()
+			}
+		}
+		property.Date = condition
+
+	case *notionapi.CheckboxPropertyConfig:
+		checkbox, err := parse.ParseCheckbox(c.Value)
+		if err != nil {
+			return errors.Wrap(errors.KindValidation, err, "invalid filter value for '%s'", c.PropName)
+		}
+		if c.Op != "=" && c.Op != "!=" {
+			return invalid()
+		}
+		// the API client omits false booleans,
+		// so we have to phrase the condition in terms of true
+		if checkbox.Checkbox == (c.Op == "=") {
+			property.Checkbox = &notionapi.CheckboxFilterCondition{Equals: true}
+		} else {
+			property.Checkbox = &notionapi.CheckboxFilterCondition{DoesNotEqual: true}
+		}
+
+	case *notionapi.RelationPropertyConfig:
+		condition := &notionapi.RelationFilterCondition{}
+		switch {
+		case c.Value == "" && (c.Op == "=" || c.Op == "!="):
+			condition.IsEmpty = c.Op == "="
+			condition.IsNotEmpty = c.Op == "!="
+		case !parse.IsObjectID(c.Value):
+			return errors.New(errors.KindValidation, "relation filters take a page ID, got '%s'", c.Value)
+		case c.Op == "=" || c.Op == "~":
+			condition.Contains = c.Value
+		case c.Op == "!=" || c.Op == "!~":
+			condition.DoesNotContain = c.Value
+		default:
+			return invalid()
+		}
+		property.Relation = condition
+
+	case *notionapi.NumberPropertyConfig:
+		// the API client always sends the "or equal to" conditions,
+		// so numbers are compared locally instead
+		return f.addNumber(c, invalid)
+
+	default:
+		// everything else is compared as it's formatted
+		return f.addText(c, invalid)
+	}
+
+	f.api = append(f.api, property)
+	return nil
+}
+
+func (f *Filter) addTimestamp(c clause, get func(page *notionapi.Page) time.Time) error {
+	date, err := parseDate(c)
+	if err != nil {
+		return err
+	}
+	compare, ok := comparison(c.Op)
+	if !ok {
+		return errors.New(errors.KindValidation, "operator '%s' can't be used with '%s'", c.Op, c.PropName)
+	}
+	f.local = append(f.local, func(page *notionapi.Page) bool {
+		timestamp := get(page)
+		switch {
+		case timestamp.Before(date):
+			return compare(-1)
+		case timestamp.After(date):
+			return compare(1)
+		}
+		return compare(0)
+	})
+	return nil
+}
+
+func (f *Filter) addNumber(c clause, invalid func() error) error {
+	if c.Value == "" {
+		return f.addText(c, invalid)
+	}
+	number, err := strconv.ParseFloat(c.Value, 64)
+	if err != nil {
+		return errors.Wrap(errors.KindValidation, err, "invalid filter value for '%s'", c.PropName)
+	}
+	compare, ok := comparison(c.Op)
+	if !ok {
+		return invalid()
+	}
+	f.local = append(f.local, func(page *notionapi.Page) bool {
+		property, ok := page.Properties[c.PropName].(*notionapi.NumberProperty)
+		if !ok {
+			return false
+		}
+		switch {
+		case property.Number < number:
+			return compare(-1)
+		case property.Number > number:
+			return compare(1)
+		}
+		return compare(0)
+	})
+	return nil
+}
+
+func (f *Filter) addText(c clause, invalid func() error) error {
+	var match func(value string) bool
+	switch c.Op {
+	case "=":
+		match = func(value string) bool { return strings.EqualFold(value, c.Value) }
+	case "!=":
+		match = func(value string) bool { return !strings.EqualFold(value, c.Value) }
+	case "~":
+		match = func(value string) bool { return strings.Contains(strings.ToLower(value), strings.ToLower(c.Value)) }
+	case "!~":
+		match = func(value string) bool { return !strings.Contains(strings.ToLower(value), strings.ToLower(c.Value)) }
+	default:
+		return invalid()
+	}
+	f.local = append(f.local, func(page *notionapi.Page) bool {
+		return match(database.FormatProperty(page.Properties[c.PropName]))
+	})
+	return nil
+}
+
+// comparison turns an operator into a check on the result of a comparison,
+// which is negative, zero, or positive like strings.Compare.
+func comparison(op string) (func(result int) bool, bool) {
+	switch op {
+	case "=":
+		return func(result int) bool { return result == 0 }, true
+	case "!=":
+		return func(result int) bool { return result != 0 }, true
+	case "<":
+		return func(result int) bool { return result < 0 }, true
+	case "<=":
+		return func(result int) bool { return result <= 0 }, true
+	case ">":
+		return func(result int) bool { return result > 0 }, true
+	case ">=":
+		return func(result int) bool { return result >= 0 }, true
+	}
+	return nil, false
+}
+
+// optionName finds the option which value names, ignoring case,
+// so that typos are caught before we query for something that can't match.
+func optionName(c clause, options []notionapi.Option) (string, error) {
+	if c.Value == "" {
+		return "", nil
+	}
+	for _, option := range options {
+		if strings.EqualFold(option.Name, c.Value) {
+			return option.Name, nil
+		}
+	}
+	return "", errors.New(errors.KindValidation, "'%s' is not an option of '%s'", c.Value, c.PropName)
+}
+
+func parseDate(c clause) (time.Time, error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	date, err := parse.ParseDate(c.Value, today)
+	if err != nil || date == nil {
+		return time.Time{}, errors.New(errors.KindValidation, "could not understand '%s' as a date for '%s'", c.Value, c.PropName)
+	}
+	return time.Time(*date.Date.Start), nil
+}
+
+// Request builds the query for the clauses the API can filter on.
+func (f *Filter) Request() *notionapi.DatabaseQueryRequest {
+	switch len(f.api) {
+	case 0:
+		return &notionapi.DatabaseQueryRequest{}
+	case 1:
+		return &notionapi.DatabaseQueryRequest{PropertyFilter: &f.api[0]}
+	}
+	return &notionapi.DatabaseQueryRequest{
+		CompoundFilter: &notionapi.CompoundFilter{notionapi.FilterOperatorAND: f.api},
+	}
+}
+
+// Match checks a page against the clauses the API can't filter on.
+func (f *Filter) Match(page *notionapi.Page) bool {
+	for _, match := range f.local {
+		if !match(page) {
+			return false
+		}
+	}
+	return true
+}
+
+// Query returns every page in the configured database which matches.
+func (f *Filter) Query(config *config.Config, client *notionapi.Client) ([]notionapi.Page, error) {
+	pages, err := database.QueryAll(config, client, f.Request())
+	if err != nil {
+		return nil, err
+	}
+
+	matched := []notionapi.Page{}
+	for _, page := range pages {
+		if f.Match(&page) {
+			matched = append(matched, page)
+		}
+	}
+	return matched, nil
+}
@@ -3,20 +3,20 @@ module github.com/crockeo/notion-cli
 go 1.17
 
 require (
+	github.com/chzyer/readline v0.0.0-20180603132655-2972be24d48e
+	github.com/gomarkdown/markdown v0.0.0-20211212230626-5af6ad2f47df
 	github.com/jomei/notionapi v1.7.1
 	github.com/manifoldco/promptui v0.9.0
+	github.com/nyaruka/phonenumbers v1.0.73
 	github.com/olebedev/when v0.0.0-20211212231525-59bd4edcf9d6
+	gopkg.in/yaml.v2 v2.4.0
 )
 
 require (
 	github.com/AlekSi/pointer v1.0.0 // indirect
-	github.com/chzyer/readline v0.0.0-20180603132655-2972be24d48e // indirect
 	github.com/golang/protobuf v1.3.2 // indirect
-	github.com/gomarkdown/markdown v0.0.0-20211212230626-5af6ad2f47df // indirect
-	github.com/nyaruka/phonenumbers v1.0.73 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/stretchr/testify v1.7.0 // indirect
 	golang.org/x/sys v0.0.0-20181122145206-62eef0e2fa9b // indirect
 	golang.org/x/text v0.3.7 // indirect
-	gopkg.in/yaml.v2 v2.4.0 // indirect
 )
@@ -3,35 +3,48 @@ package main
 import (
 	"os"
 
-	"github.com/jomei/notionapi"
-
 	"github.com/crockeo/notion-cli/commands"
+	"github.com/crockeo/notion-cli/commands/agenda"
+	"github.com/crockeo/notion-cli/commands/appendbody"
+	"github.com/crockeo/notion-cli/commands/archive"
+	"github.com/crockeo/notion-cli/commands/bulkset"
 	"github.com/crockeo/notion-cli/commands/capture"
 	"github.com/crockeo/notion-cli/commands/complete"
+	"github.com/crockeo/notion-cli/commands/completion"
 	"github.com/crockeo/notion-cli/commands/dump"
-	"github.com/crockeo/notion-cli/config"
+	"github.com/crockeo/notion-cli/commands/ical"
+	"github.com/crockeo/notion-cli/commands/importer"
+	"github.com/crockeo/notion-cli/commands/journal"
+	"github.com/crockeo/notion-cli/commands/mdsync"
+	"github.com/crockeo/notion-cli/commands/schema"
+	"github.com/crockeo/notion-cli/commands/search"
+	"github.com/crockeo/notion-cli/commands/serve"
+	"github.com/crockeo/notion-cli/commands/tui"
+	"github.com/crockeo/notion-cli/commands/undo"
 )
 
 func main() {
-	config, err := config.Load()
-	commands.Guard(err)
-	client := notionapi.NewClient(config.Token)
-
-	args := os.Args[1:]
-	if len(args) == 0 {
-		commands.PrintHelp()
-		os.Exit(1)
-	}
-
-	command := args[0]
-	if command == "capture" {
-		capture.Capture(config, client, args[1:])
-	} else if command == "complete" {
-		complete.Complete(config, client, args[1:])
-	} else if command == "dump" {
-		dump.Dump(config, client, args[1:])
-	} else {
-		commands.PrintHelp()
-		os.Exit(1)
-	}
+	commands.Register(
+		capture.Command,
+		complete.Command,
+		dump.Command,
+		schema.Command,
+		agenda.Command,
+		tui.Command,
+		search.Command,
+		archive.Command,
+		archive.RestoreCommand,
+		bulkset.Command,
+		appendbody.Command,
+		journal.Command,
+		mdsync.PullCommand,
+		mdsync.PushCommand,
+		importer.Command,
+		ical.Command,
+		serve.Command,
+		undo.Command,
+		undo.UncompleteCommand,
+		completion.Command,
+	)
+	commands.Main(os.Args[1:])
 }
@@ -0,0 +1,127 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jomei/notionapi"
+)
+
+// FromBlocks renders Notion blocks as Markdown,
+// as the rough inverse of ToBlocks.
+// blocks which don't have a Markdown equivalent are skipped.
+func FromBlocks(blocks []notionapi.Block) []byte {
+	var builder strings.Builder
+	var previous notionapi.BlockType
+	for _, block := range blocks {
+		text, ok := fromBlock(block)
+		if !ok {
+			continue
+		}
+
+		// consecutive list items belong to the same list,
+		// everything else is separated by a blank line
+		if builder.Len() > 0 {
+			if isListItem(block.GetType()) && block.GetType() == previous {
+				builder.WriteString("\n")
+			} else {
+				builder.WriteString("\n\n")
+			}
+		}
+		builder.WriteString(text)
+		previous = block.GetType()
+	}
+	if builder.Len() > 0 {
+		builder.WriteString("\n")
+	}
+	return []byte(builder.String())
+}
+
+func fromBlock(block notionapi.Block) (string, bool) {
+	switch block := block.(type) {
+	case *notionapi.ParagraphBlock:
+		return FromRichText(block.Paragraph.Text), true
+	case *notionapi.Heading1Block:
+		return "# " + FromRichText(block.Heading1.Text), true
+	case *notionapi.Heading2Block:
+		return "## " + FromRichText(block.Heading2.Text), true
+	case *notionapi.Heading3Block:
+		return "### " + FromRichText(block.Heading3.Text), true
+	case *notionapi.BulletedListItemBlock:
+		return "- " + FromRichText(block.BulletedListItem.Text), true
+	case *notionapi.NumberedListItemBlock:
+		return "1. " + FromRichText(block.NumberedListItem.Text), true
+	case *notionapi.ToDoBlock:
+		if block.ToDo.Checked {
+			return "- [x] " + FromRichText(block.ToDo.Text), true
+		}
+		return "- [ ] " + FromRichText(block.ToDo.Text), true
+	case *notionapi.ToggleBlock:
+		return "- " + FromRichText(block.Toggle.Text), true
+	case *notionapi.QuoteBlock:
+		return "> " + FromRichText(block.Quote.Text), true
+	case *notionapi.CalloutBlock:
+		return "> " + FromRichText(block.Callout.Text), true
+	case *notionapi.CodeBlock:
+		return fmt.Sprintf("```%s\n%s\n```", block.Code.Language, FromRichText(block.Code.Text)), true
+	case *notionapi.DividerBlock:
+		return "---", true
+	case *notionapi.ImageBlock:
+		return fmt.Sprintf("![%s](%s)", FromRichText(block.Image.Caption), block.Image.GetURL()), true
+	}
+	return "", false
+}
+
+func isListItem(blockType notionapi.BlockType) bool {
+	return blockType == notionapi.BlockTypeBulletedListItem ||
+		blockType == notionapi.BlockTypeNumberedListItem ||
+		blockType == notionapi.BlockTypeToDo ||
+		blockType == notionapi.BlockTypeToggle
+}
+
+// FromRichText renders rich text as inline Markdown.
+func FromRichText(richTexts []notionapi.RichText) string {
+	var builder strings.Builder
+	for _, richText := range richTexts {
+		text := richText.PlainText
+		if text == "" {
+			text = richText.Text.Content
+		}
+
+		if annotations := richText.Annotations; annotations != nil {
+			if annotations.Code {
+				text = "`" + text + "`"
+			}
+			if annotations.Bold {
+				text = "**" + text + "**"
+			}
+			if annotations.Italic {
+				text = "*" + text + "*"
+			}
+			if annotations.Strikethrough {
+				text = "~~" + text + "~~"
+			}
+		}
+
+		if richText.Text.Link != nil {
+			text = fmt.Sprintf("[%s](%s)", text, richText.Text.Link.Url)
+		} else if richText.Href != "" {
+			text = fmt.Sprintf("[%s](%s)", text, richText.Href)
+		}
+
+		builder.WriteString(text)
+	}
+	return builder.String()
+}
+
+// Lossless reports whether FromBlocks renders every one of blocks,
+// i.e. whether replacing them with what it renders would lose anything.
+// nested blocks aren't rendered, so any block with children isn't lossless.
+func Lossless(blocks []notionapi.Block) bool {
+	for _, block := range blocks {
+		if _, ok := fromBlock(block); !ok || block.GetHasChildren() {
+			return false
+		}
+	}
+	return true
+}
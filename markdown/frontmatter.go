@@ -0,0 +1,58 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+var frontmatterFence = []byte("---\n")
+
+// SplitFrontmatter separates the YAML frontmatter at the start of a
+// Markdown document from its body.
+// ok is false when the document doesn't start with frontmatter,
+// in which case the whole document is the body.
+func SplitFrontmatter(contents []byte) (frontmatter []byte, body []byte, ok bool) {
+	contents = bytes.ReplaceAll(contents, []byte("\r\n"), []byte("\n"))
+	if !bytes.HasPrefix(contents, frontmatterFence) {
+		return nil, contents, false
+	}
+
+	rest := contents[len(frontmatterFence):]
+	if bytes.HasPrefix(rest, frontmatterFence) {
+		return []byte{}, rest[len(frontmatterFence):], true
+	}
+	end := bytes.Index(rest, []byte("\n"+string(frontmatterFence)))
+	if end < 0 {
+		return nil, contents, false
+	}
+	return rest[:end+1], rest[end+1+len(frontmatterFence):], true
+}
+
+// JoinFrontmatter puts YAML frontmatter in front of a Markdown body.
+func JoinFrontmatter(frontmatter []byte, body []byte) []byte {
+	joined := append([]byte{}, frontmatterFence...)
+	joined = append(joined, frontmatter...)
+	if len(frontmatter) > 0 && frontmatter[len(frontmatter)-1] != '\n' {
+		joined = append(joined, '\n')
+	}
+	joined = append(joined, frontmatterFence...)
+	return append(joined, body...)
+}
+
+// FrontmatterString renders a value decoded from YAML frontmatter
+// as the string parse.Property expects, so that Done: true
+// and Tags: [a, b] read the same as their quoted forms.
+func FrontmatterString(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		values := make([]string, len(value))
+		for i, item := range value {
+			values[i] = FrontmatterString(item)
+		}
+		return strings.Join(values, ",")
+	}
+	return fmt.Sprint(value)
+}
@@ -30,11 +30,7 @@ func transform(node ast.Node) ([]notionapi.Block, error) {
 	case *ast.List:
 		blocks, err = transformList(node)
 	default:
-		if t := reflect.TypeOf(node); t.Kind() == reflect.Ptr {
-			fmt.Println("*" + t.Elem().Name())
-		} else {
-			fmt.Println(t.Name())
-		}
+		// nodes we don't support yet are left out of the page
 	}
 
 	return blocks, err
@@ -193,3 +189,44 @@ func transformText(node *ast.Text) notionapi.RichText {
 		},
 	}
 }
+
+// Heading builds a heading block out of plain text.
+func Heading(level int, text string) notionapi.Block {
+	nodes, _ := transformHeading(&ast.Heading{
+		Level: level,
+		Container: ast.Container{
+			Children: []ast.Node{&ast.Text{Leaf: ast.Leaf{Literal: []byte(text)}}},
+		},
+	})
+	return nodes[0]
+}
+
+// ToTodos turns paragraphs and list items into unchecked to-dos,
+// leaving every other block as it is.
+func ToTodos(blocks []notionapi.Block) []notionapi.Block {
+	todos := make([]notionapi.Block, len(blocks))
+	for i, block := range blocks {
+		var text []notionapi.RichText
+		switch block := block.(type) {
+		case *notionapi.ParagraphBlock:
+			text = block.Paragraph.Text
+		case *notionapi.BulletedListItemBlock:
+			text = block.BulletedListItem.Text
+		case *notionapi.NumberedListItemBlock:
+			text = block.NumberedListItem.Text
+		default:
+			todos[i] = block
+			continue
+		}
+		todos[i] = &notionapi.ToDoBlock{
+			BasicBlock: notionapi.BasicBlock{
+				Object: "block",
+				Type:   notionapi.BlockTypeToDo,
+			},
+			ToDo: notionapi.ToDo{
+				Text: text,
+			},
+		}
+	}
+	return todos
+}
@@ -1,7 +1,6 @@
 package parse
 
 import (
-	"fmt"
 	"regexp"
 	"time"
 
@@ -41,7 +40,6 @@ func ExactMonthDateBiasNextYear(s rules.Strategy) rules.Rule {
 			}
 			parsedDate := time.Date(year, time.Month(*c.Month), parsedDay, 0, 0, 0, 0, ref.Location())
 			roundedDate := time.Date(year, month, day, 0, 0, 0, 0, ref.Location())
-			fmt.Println(parsedDate, roundedDate)
 			if parsedDate.Before(roundedDate) {
 				year = year + 1
 				c.Year = &year
@@ -83,3 +81,15 @@ func (td *TimelessDate) MarshalJSON() ([]byte, error) {
 	result := date.Format(format)
 	return []byte("\"" + result + "\""), nil
 }
+
+// FromNotionDate converts a date we got back from the API into local time.
+// the API sends dates without times as bare dates (e.g. "2021-12-13"),
+// which come back to us as midnight UTC, so we move those to local midnight
+// and report that they're timeless, like a TimelessDate we would have sent.
+func FromNotionDate(date *notionapi.Date) (time.Time, bool) {
+	t := time.Time(*date)
+	if t.Location() == time.UTC && t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0 {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local), true
+	}
+	return t.Local(), false
+}
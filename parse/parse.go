@@ -44,6 +44,8 @@ func Property(propName string, propConfig notionapi.PropertyConfig, propValue st
 		property, err = ParseEmail(propValue)
 	case *notionapi.PhoneNumberPropertyConfig:
 		property, err = ParsePhoneNumber(propValue)
+	case *notionapi.RelationPropertyConfig:
+		property, err = ParseRelation(propValue)
 	default:
 		err = errors.NewInvalidPropertyConfig(string(propConfig.GetType()))
 	}
@@ -94,7 +96,31 @@ func ParseSelect(candidate string, options []notionapi.Option) (*notionapi.Selec
 }
 
 func ParseMultiSelect(candidate string, options []notionapi.Option) (*notionapi.MultiSelectProperty, error) {
-	return nil, errors.NewFailedParse(candidate, "multi_select")
+	// multiple options are separated by commas,
+	// and the empty string means "no options"
+	selected := []notionapi.Option{}
+	for _, name := range strings.Split(candidate, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		found := false
+		for _, option := range options {
+			if name == option.Name {
+				selected = append(selected, option)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, errors.NewFailedParse(name, "multi_select")
+		}
+	}
+
+	return &notionapi.MultiSelectProperty{
+		MultiSelect: selected,
+	}, nil
 }
 
 func ParseDate(candidate string, now time.Time) (*DateProperty, error) {
@@ -102,6 +128,50 @@ func ParseDate(candidate string, now time.Time) (*DateProperty, error) {
 		return nil, nil
 	}
 
+	if date, ok := parseISODate(candidate); ok {
+		return newDateProperty(date), nil
+	}
+
+	result, err := parseWhen(candidate, now)
+	if err != nil {
+		return nil, err
+	}
+	return newDateProperty(result.Time), nil
+}
+
+// ParseDateExact behaves like ParseDate,
+// except that it fails when only part of the candidate is a date
+// (e.g. "tomorrow buy milk")
+func ParseDateExact(candidate string, now time.Time) (*DateProperty, error) {
+	if date, ok := parseISODate(candidate); ok {
+		return newDateProperty(date), nil
+	}
+
+	result, err := parseWhen(candidate, now)
+	if err != nil {
+		return nil, err
+	}
+	if result.Index != 0 || len(strings.TrimSpace(result.Text)) != len(strings.TrimSpace(candidate)) {
+		return nil, errors.NewFailedParse(candidate, "date")
+	}
+	return newDateProperty(result.Time), nil
+}
+
+// parseISODate reads dates in the formats we write them in
+// (see database.FormatProperty), which when misreads as times of day.
+// dates without times are local midnight, like the ones when returns.
+func parseISODate(candidate string) (time.Time, bool) {
+	candidate = strings.TrimSpace(candidate)
+	if date, err := time.ParseInLocation("2006-01-02", candidate, time.Local); err == nil {
+		return date, true
+	}
+	if date, err := time.Parse(time.RFC3339, candidate); err == nil {
+		return date, true
+	}
+	return time.Time{}, false
+}
+
+func parseWhen(candidate string, now time.Time) (*when.Result, error) {
 	parser := when.Parser{}
 	parser.Add(ExactMonthDateBiasNextYear(rules.Override))
 	parser.Add(en.All...)
@@ -113,13 +183,16 @@ func ParseDate(candidate string, now time.Time) (*DateProperty, error) {
 	if result == nil {
 		return nil, errors.NewFailedParse(candidate, "date")
 	}
-	date := result.Time.Round(0)
+	return result, nil
+}
 
+func newDateProperty(date time.Time) *DateProperty {
+	date = date.Round(0)
 	return &DateProperty{
 		Date: DateObject{
 			Start: (*TimelessDate)(&date),
 		},
-	}, nil
+	}
 }
 
 func ParseCheckbox(candidate string) (*notionapi.CheckboxProperty, error) {
@@ -186,3 +259,37 @@ func ParsePhoneNumber(candidate string) (*notionapi.PhoneNumberProperty, error)
 		PhoneNumber: candidate,
 	}, nil
 }
+
+func ParseRelation(candidate string) (*notionapi.RelationProperty, error) {
+	// relations are written as a comma separated list of page IDs.
+	// callers which only know page titles need to resolve them first
+	relations := []notionapi.Relation{}
+	for _, id := range strings.Split(candidate, ",") {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		if !IsObjectID(id) {
+			return nil, errors.NewFailedParse(id, "relation")
+		}
+		relations = append(relations, notionapi.Relation{ID: notionapi.PageID(id)})
+	}
+	return &notionapi.RelationProperty{
+		Relation: relations,
+	}, nil
+}
+
+// IsObjectID reports whether candidate looks like a Notion ID,
+// i.e. 32 hex digits with or without the UUID dashes
+func IsObjectID(candidate string) bool {
+	id := strings.Replace(candidate, "-", "", -1)
+	if len(id) != 32 {
+		return false
+	}
+	for _, c := range id {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return false
+		}
+	}
+	return true
+}
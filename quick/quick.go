@@ -0,0 +1,136 @@
+package quick
+
+import (
+	"strings"
+	"time"
+
+	"github.com/jomei/notionapi"
+
+	"github.com/crockeo/notion-cli/errors"
+	"github.com/crockeo/notion-cli/parse"
+)
+
+// Result is a one-line capture split into its parts.
+// Values holds the raw text for each property named by a sigil,
+// which still needs to go through parse.Property.
+type Result struct {
+	Title  string
+	Values map[string]string
+}
+
+// Parse splits a quick capture line like
+//
+//	Renew passport #admin !high @next tuesday +Personal
+//
+// into a title and property values, using sigils to map
+// the prefix of a word onto the name of a property.
+// words which don't start with a sigil make up the title.
+func Parse(line string, sigils map[string]string, database *notionapi.Database) (*Result, error) {
+	words := strings.Fields(line)
+	title := []string{}
+	values := map[string][]string{}
+
+	now := time.Now()
+	now = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	for i := 0; i < len(words); {
+		sigil, propName, ok := matchSigil(words[i], sigils)
+		if !ok {
+			title = append(title, words[i])
+			i++
+			continue
+		}
+
+		propConfig, ok := database.Properties[propName]
+		if !ok {
+			return nil, errors.New(errors.KindConfig, "sigil '%s' refers to propName which doesn't exist '%s'", sigil, propName)
+		}
+
+		rest := append([]string{strings.TrimPrefix(words[i], sigil)}, words[i+1:]...)
+		value, n, err := consume(propConfig, rest, now)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(values[propName]) > 0 {
+			if _, ok := propConfig.(*notionapi.MultiSelectPropertyConfig); !ok {
+				if _, ok := propConfig.(*notionapi.RelationPropertyConfig); !ok {
+					return nil, errors.New(errors.KindValidation, "property '%s' was given more than once", propName)
+				}
+			}
+		}
+		values[propName] = append(values[propName], value)
+		i += n
+	}
+
+	result := &Result{
+		Title:  strings.Join(title, " "),
+		Values: map[string]string{},
+	}
+	for propName, propValues := range values {
+		result.Values[propName] = strings.Join(propValues, ",")
+	}
+	return result, nil
+}
+
+// matchSigil finds the longest sigil which prefixes word.
+// a sigil on its own (e.g. a trailing "!") is part of the title.
+func matchSigil(word string, sigils map[string]string) (string, string, bool) {
+	bestSigil := ""
+	bestPropName := ""
+	for sigil, propName := range sigils {
+		if len(sigil) > len(bestSigil) && len(word) > len(sigil) && strings.HasPrefix(word, sigil) {
+			bestSigil = sigil
+			bestPropName = propName
+		}
+	}
+	return bestSigil, bestPropName, bestSigil != ""
+}
+
+// consume figures out how many of words belong to the value of a sigil,
+// returning the value and the number of words it used.
+func consume(propConfig notionapi.PropertyConfig, words []string, now time.Time) (string, int, error) {
+	if strings.HasPrefix(words[0], "\"") {
+		// quoted values run until the closing quote
+		// so that they can contain spaces
+		for n := 1; n <= len(words); n++ {
+			value := strings.Join(words[:n], " ")
+			if len(value) > 1 && strings.HasSuffix(value, "\"") {
+				return strings.Trim(value, "\""), n, nil
+			}
+		}
+		return "", 0, errors.New(errors.KindValidation, "unterminated quote in '%s'", strings.Join(words, " "))
+	}
+
+	switch propConfig := propConfig.(type) {
+	case *notionapi.SelectPropertyConfig:
+		return consumeOption(propConfig.Select.Options, words)
+	case *notionapi.MultiSelectPropertyConfig:
+		return consumeOption(propConfig.MultiSelect.Options, words)
+	case *notionapi.DatePropertyConfig:
+		// dates are usually multiple words ("next tuesday"),
+		// so we take the longest run of words that reads as a date
+		for n := len(words); n > 0; n-- {
+			if _, err := parse.ParseDateExact(strings.Join(words[:n], " "), now); err == nil {
+				return strings.Join(words[:n], " "), n, nil
+			}
+		}
+		return "", 0, errors.NewFailedParse(words[0], "date")
+	}
+
+	return words[0], 1, nil
+}
+
+// consumeOption matches the longest run of words
+// against the names of options, ignoring case.
+func consumeOption(options []notionapi.Option, words []string) (string, int, error) {
+	for n := len(words); n > 0; n-- {
+		candidate := strings.Join(words[:n], " ")
+		for _, option := range options {
+			if strings.EqualFold(candidate, option.Name) {
+				return option.Name, n, nil
+			}
+		}
+	}
+	return words[0], 1, nil
+}
@@ -0,0 +1,158 @@
+package quick
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jomei/notionapi"
+
+	"github.com/crockeo/notion-cli/errors"
+)
+
+func testDatabase() *notionapi.Database {
+	return &notionapi.Database{
+		Properties: notionapi.PropertyConfigs{
+			"Name": &notionapi.TitlePropertyConfig{Type: notionapi.PropertyConfigTypeTitle},
+			"Tags": &notionapi.MultiSelectPropertyConfig{
+				Type: notionapi.PropertyConfigTypeMultiSelect,
+				MultiSelect: notionapi.Select{Options: []notionapi.Option{
+					{Name: "admin"},
+					{Name: "Deep Work"},
+				}},
+			},
+			"Priority": &notionapi.SelectPropertyConfig{
+				Type: notionapi.PropertyConfigTypeSelect,
+				Select: notionapi.Select{Options: []notionapi.Option{
+					{Name: "High"},
+					{Name: "Low"},
+				}},
+			},
+			"Due":     &notionapi.DatePropertyConfig{Type: notionapi.PropertyConfigTypeDate},
+			"Project": &notionapi.RichTextPropertyConfig{Type: notionapi.PropertyConfigTypeRichText},
+		},
+	}
+}
+
+var testSigils = map[string]string{
+	"#":  "Tags",
+	"!":  "Priority",
+	"@":  "Due",
+	"+":  "Project",
+	"!!": "Tags",
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		sigils map[string]string
+		title  string
+		values map[string]string
+		kind   errors.Kind
+	}{
+		{
+			name:   "no sigils",
+			line:   "Water the plants",
+			title:  "Water the plants",
+			values: map[string]string{},
+		},
+		{
+			name:   "options ignore case",
+			line:   "Renew passport #ADMIN !high",
+			title:  "Renew passport",
+			values: map[string]string{"Tags": "admin", "Priority": "High"},
+		},
+		{
+			name:   "multi-word option",
+			line:   "Write #deep work the report",
+			title:  "Write the report",
+			values: map[string]string{"Tags": "Deep Work"},
+		},
+		{
+			name:   "unknown option is a single word",
+			line:   "Plan #garden beds",
+			title:  "Plan beds",
+			values: map[string]string{"Tags": "garden"},
+		},
+		{
+			name:   "multi-select given twice",
+			line:   "Sort papers #admin #deep work",
+			title:  "Sort papers",
+			values: map[string]string{"Tags": "admin,Deep Work"},
+		},
+		{
+			name:   "longest date phrase wins",
+			line:   "Call mom @next tuesday please",
+			title:  "Call mom please",
+			values: map[string]string{"Due": "next tuesday"},
+		},
+		{
+			name:   "quoted value",
+			line:   `Draft outline +"Big Project" #admin`,
+			title:  "Draft outline",
+			values: map[string]string{"Project": "Big Project", "Tags": "admin"},
+		},
+		{
+			name:   "longest sigil wins",
+			line:   "Ship it !!admin",
+			title:  "Ship it",
+			values: map[string]string{"Tags": "admin"},
+		},
+		{
+			name:   "unknown sigil is part of the title",
+			line:   "Pay %rent",
+			title:  "Pay %rent",
+			values: map[string]string{},
+		},
+		{
+			name:   "bare sigil is part of the title",
+			line:   "Tag this # later !",
+			title:  "Tag this # later !",
+			values: map[string]string{},
+		},
+		{
+			name: "select given twice",
+			line: "Triage !high !low",
+			kind: errors.KindValidation,
+		},
+		{
+			name: "unterminated quote",
+			line: `Draft +"Big Project`,
+			kind: errors.KindValidation,
+		},
+		{
+			name:   "sigil for a missing property",
+			line:   "Buy milk ~groceries",
+			sigils: map[string]string{"~": "Errands"},
+			kind:   errors.KindConfig,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sigils := test.sigils
+			if sigils == nil {
+				sigils = testSigils
+			}
+			result, err := Parse(test.line, sigils, testDatabase())
+			if test.kind != "" {
+				if err == nil {
+					t.Fatalf("expected a %s error, got %#v", test.kind, result)
+				}
+				if kind := errors.KindOf(err); kind != test.kind {
+					t.Fatalf("expected a %s error, got %s: %s", test.kind, kind, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Title != test.title {
+				t.Errorf("expected the title '%s', got '%s'", test.title, result.Title)
+			}
+			if !reflect.DeepEqual(result.Values, test.values) {
+				t.Errorf("expected the values %v, got %v", test.values, result.Values)
+			}
+		})
+	}
+}